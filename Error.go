@@ -8,8 +8,13 @@ package error
 
 import (
 	"bytes"
-	"encoding/json"
+	"errors"
 	"fmt"
+	"net/http"
+	"reflect"
+	"runtime"
+	"strings"
+	"time"
 )
 
 // Error is the center of this package and is a concrete representation of our errors.
@@ -23,37 +28,423 @@ import (
 type Error struct {
 	// Machine-readable error code.
 	// Example: ENOTFOUND, EEXISTS.
-	Kind string
+	Kind string `json:"kind,omitempty"`
+
+	// Stable numeric code, for clients which can't rely on Kind. Errors
+	// without one use the code of their kind, see RegisterCode.
+	Code int `json:"code,omitempty"`
 
 	// HTTP status code.
-	Status int
+	Status int `json:"status,omitempty"`
 
 	// Error message.
 	// This could be human-readable, or a JSON response. Ex: { "detail": "Wrong password" }.
-	Message string
+	Message string `json:"message,omitempty"`
+
+	// UserMessage is the message shown to clients instead of Message, which
+	// is then free to describe the error in detail for operators. It must be
+	// client-safe, it is sent even for server errors. See ClientSafeMessage.
+	// Ex: "Username is already in use.", with the Message
+	// "unique_violation on users_username_key".
+	UserMessage string `json:"-"`
+
+	// AllowMessage marks Message as vetted for clients, so that it is sent
+	// even for a server error (5xx), whose messages are otherwise replaced
+	// with the default message, see ClientSafeMessage.
+	// Ex: "Payment provider temporarily unavailable."
+	AllowMessage bool `json:"-"`
+
+	// MessageKey, when registered, renders the message of the error from a
+	// template with Params as data, instead of Message, see RegisterMessage.
+	// Params may hold internal data, neither is serialized.
+	// Ex: "user.not_found", map[string]interface{}{"id": 42}.
+	MessageKey string                 `json:"-"`
+	Params     map[string]interface{} `json:"-"`
+
+	// Detail elaborates on Message for the end user, so UIs can show Message
+	// as a title and Detail as its body. Like Message it must be client-safe.
+	// Ex: "Your card was declined, try another payment method."
+	Detail string `json:"detail,omitempty"`
+
+	// Hint is aimed at developers integrating the API, rather than end users.
+	// Ex: "Pass ?include=profile to expand this field."
+	//
+	// Hints are never localized nor shown to end users.
+	Hint string `json:"hint,omitempty"`
+
+	// Remediation tells the end user how to resolve the error, so clients
+	// can style it apart from Message and localize it independently. Like
+	// Message it must be client-safe, and unlike Hint it is meant for end
+	// users.
+	// Ex: "Try searching by email instead."
+	Remediation string `json:"remediation,omitempty"`
+
+	// DocsURL links to the documentation of the error for API consumers.
+	// Errors without one link to the page of their kind if a base URL is
+	// set, see SetDocsBaseURL.
+	// Ex: "https://docs.example.com/errors/item_does_not_exist".
+	DocsURL string `json:"docs_url,omitempty"`
+
+	// Details holds structured data about the error for the client.
+	// Ex: { "param": "limit", "min": 1, "max": 100 }.
+	Details map[string]interface{} `json:"details,omitempty"`
+
+	// ErrorDetails holds typed data about the error for the client, such as
+	// the FieldViolation of each invalid field. See ErrorDetail.
+	ErrorDetails []ErrorDetail `json:"error_details,omitempty"`
+
+	// Location is the URL path of a related resource, sent in the Location
+	// header, such as the existing resource of an EEXIST error.
+	// Ex: "/users/42".
+	Location string `json:"-"`
+
+	// ContentType, when set, replaces the JSON of the response with the
+	// client-safe message as is, sent with this Content-Type, e.g.
+	// "text/plain; charset=utf-8" for health checks. Unknown content types
+	// are trusted.
+	ContentType string `json:"-"`
+
+	// Fields holds structured data about the error for operators, such as
+	// the IDs of the user and entities involved. They are logged, see
+	// LogAttrs, but never serialized, use Details for data meant for the
+	// client.
+	Fields map[string]interface{} `json:"-"`
+
+	// Headers are extra headers of the response. Values are sanitized, and
+	// invalid headers skipped, see ResponseHeaders.
+	Headers map[string]string `json:"-"`
+
+	// Severity overrides the level the error is logged at by Handler, which
+	// is otherwise derived from its status, see SeverityOf.
+	Severity Severity `json:"-"`
+
+	// Time is when the error occurred. It is set by the constructors, such as
+	// NewError and Wrap, see ErrorTime.
+	Time time.Time `json:"-"`
+
+	// RequestID and TraceID correlate the error with the inbound request and
+	// the distributed trace it occurred in, see ErrorRequestID and
+	// ErrorTraceID. They are logged but never serialized.
+	RequestID string `json:"-"`
+	TraceID   string `json:"-"`
+
+	// Tags categorize the error across kinds, e.g. to route it to the right
+	// on-call team. They are logged but never serialized, see ErrorTags.
+	// Ex: "billing", "beta-feature", "tenant:acme".
+	Tags []string `json:"-"`
+
+	// Sensitive marks the Message, UserMessage, Detail and Params of the
+	// error as holding customer data, such as an email, so that they are
+	// removed from its Redacted copy.
+	Sensitive bool `json:"-"`
+
+	// Transient marks a temporary failure, such as a database connection
+	// blip, which is worth retrying. Internal errors which aren't transient
+	// are permanent, like a bug. See IsTransient.
+	Transient bool `json:"-"`
+
+	// Retryable, when set, overrides whether the error is worth retrying,
+	// whatever its kind or cause, see IsRetryable and WithRetryable.
+	Retryable *bool `json:"-"`
+
+	// Deprecation, when set, flags the endpoint which reported the error as
+	// deprecated, with the date it will be removed on. It is sent in the
+	// Deprecation and Sunset headers of the response, see ResponseHeaders.
+	Deprecation time.Time `json:"-"`
+
+	// IncidentID identifies the occurrence of a server error in the logs, so
+	// clients can quote it to support, see Handler.IncidentIDs.
+	IncidentID string `json:"incident_id,omitempty"`
+
+	// Causes are non-fatal errors which preceded this error during the
+	// request, see AddError.
+	Causes []*Error `json:"causes,omitempty"`
+
+	// Items are the errors of the items of a bulk operation, see BatchError.
+	Items []BatchItem `json:"items,omitempty"`
 
 	// Op is a logical operation. It denotes the operation being performed.
 	// Typically holds the name of the method or function reporting the error.
-	Op string
+	//
+	// Op is for operators only and is never serialized.
+	Op string `json:"-"`
+
+	// Ops are further logical operations of this layer, for operations
+	// naturally described by more than one label (e.g. both the HTTP route
+	// and the domain method). They are rendered in order, after Op.
+	Ops []string `json:"-"`
 
 	// Err is the original error (unmarshall errors, network errors...) which
 	// caused this error, set it to nil if there isn't any.
-	Err error
+	//
+	// Err is for operators only and is never serialized.
+	Err error `json:"-"`
+
+	// stack holds the program counters of the call stack, see Frames.
+	stack []uintptr
+
+	// sensitive holds the Details and Fields keys whose values are
+	// customer data, see MarkSensitive.
+	sensitive map[string]bool
+
+	// internal holds the Details keys never sent to clients, see
+	// MarkInternal.
+	internal map[string]bool
 }
 
+// ResponseBody returns the JSON encoding of the client-safe copy of e.
+// The raw error chain never leaves the server, see Public.
+//
+// If a ContentType is set in the chain, the body is the client-safe message
+// of e as is instead, see ClientSafeMessage.
 func (e *Error) ResponseBody() ([]byte, error) {
-	body, err := json.Marshal(e)
+	return DefaultRegistry().ResponseBody(e)
+}
+
+// ResponseHeaders returns the HTTP status code of e, resolved through the
+// chain, and the headers of the response.
+//
+// The first Headers found in the chain are included. CR and LF are stripped
+// from their values, since they may be attacker-influenced, and headers which
+// are still invalid are skipped.
+//
+// If the chain has a Deprecation date, the Deprecation and Sunset headers are
+// set so clients can detect upcoming removals on failures too.
+//
+// The first Location found in the chain is sent in the Location header,
+// provided it is a valid URL path.
+//
+// An ETag is set if enabled, see SetETags.
+func (e *Error) ResponseHeaders() (int, map[string]string) {
+	return DefaultRegistry().ResponseHeaders(e)
+}
+
+// ResponseBody is like Error.ResponseBody but resolves e with r.
+//
+// If e can't be marshaled, e.g. its Details hold a value which isn't JSON
+// serializable, the failure is logged and the body falls back to the Kind,
+// Status and Message of e so the client still gets a meaningful error.
+func (r *Registry) ResponseBody(e *Error) ([]byte, error) {
+	if r.errorContentType(e) != "" {
+		return []byte(r.truncate(r.ClientSafeMessage(e))), nil
+	}
+	pub := r.public(e)
+	body, err := marshalJSON(pub, r.indent)
+	if err == nil {
+		return body, nil
+	}
+	r.log().Warn("unable to marshal response body, falling back to kind, status and message", "error", err)
+
+	body, err = marshalJSON(Error{Kind: pub.Kind, Status: pub.Status, Message: pub.Message}, r.indent)
 	if err != nil {
 		return nil, fmt.Errorf("Error while parsing response body: %v", err)
 	}
 	return body, nil
 }
 
-func (e *Error) ResponseHeaders() (string, map[string]string) {
-	return e.Kind, map[string]string{
-		"Content-Type":           "application/json; charset=utf-8",
-		"X-Content-Type-Options": "nosniff",
+// errorContentType returns the first valid ContentType found in the chain of
+// Error.Err.
+func (r *Registry) errorContentType(err error) string {
+	if e, ok := r.lookup(err, func(e *Error) bool { return e.ContentType != "" }); ok {
+		if ct, ok := sanitizeHeaderValue(e.ContentType); ok && ct == e.ContentType {
+			return ct
+		}
+	}
+	return ""
+}
+
+// ResponseHeaders is like Error.ResponseHeaders but resolves e with r.
+func (r *Registry) ResponseHeaders(e *Error) (int, map[string]string) {
+	headers := make(map[string]string)
+	if h, ok := r.lookup(e, func(e *Error) bool { return len(e.Headers) != 0 }); ok {
+		r.sanitizeHeaders(headers, h.Headers)
+	}
+	headers["Content-Type"] = "application/json; charset=utf-8"
+	if ct := r.errorContentType(e); ct != "" {
+		headers["Content-Type"] = ct
+	}
+	headers["X-Content-Type-Options"] = "nosniff"
+	if l, ok := r.lookup(e, func(e *Error) bool { return e.Location != "" }); ok {
+		if validLocation(l.Location) {
+			headers["Location"] = l.Location
+		} else {
+			r.log().Warn("skipping invalid Location header", "location", l.Location)
+		}
+	}
+	if etag := r.etag(e); etag != "" {
+		headers["ETag"] = etag
+	}
+	if d, ok := r.lookup(e, func(e *Error) bool { return !e.Deprecation.IsZero() }); ok {
+		headers["Deprecation"] = "true"
+		headers["Sunset"] = d.Deprecation.UTC().Format(http.TimeFormat)
+	}
+	return r.ErrorStatus(e), headers
+}
+
+// layerOps returns the operations of this layer of the chain: Op, if any,
+// followed by Ops.
+func (e *Error) layerOps() []string {
+	if e.Op == "" {
+		return e.Ops
+	}
+	return append([]string{e.Op}, e.Ops...)
+}
+
+// Unwrap returns the wrapped Err, so that errors.Is and errors.As see
+// through *Error like through any standard library wrapper.
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Cause returns the wrapped Err, for codebases migrating from
+// github.com/pkg/errors, whose Cause function follows Cause methods down to
+// the root error.
+//
+// pkg/errors takes a nil Cause as the root, so an error without Err returns
+// itself behind a wrapper without a Cause method, which errors.Is and
+// errors.As see through.
+func (e *Error) Cause() error {
+	if e.Err == nil {
+		return rootError{e}
 	}
+	return e.Err
+}
+
+// rootError is an *Error without Err, returned by Cause so that the Cause
+// function of pkg/errors stops at it.
+type rootError struct {
+	e *Error
+}
+
+func (r rootError) Error() string { return r.e.Error() }
+func (r rootError) Unwrap() error { return r.e }
+
+// Clone returns a deep copy of e: its maps and slices, such as Details,
+// Fields or Ops, are copied so the copy can be modified without affecting
+// e. The wrapped Err and the Causes are shared, errors being immutable once
+// returned.
+func (e *Error) Clone() *Error {
+	c := *e
+	c.Details = cloneMap(e.Details)
+	c.Fields = cloneMap(e.Fields)
+	c.Params = cloneMap(e.Params)
+	if e.Headers != nil {
+		c.Headers = make(map[string]string, len(e.Headers))
+		for k, v := range e.Headers {
+			c.Headers[k] = v
+		}
+	}
+	if e.internal != nil {
+		c.internal = make(map[string]bool, len(e.internal))
+		for k, v := range e.internal {
+			c.internal[k] = v
+		}
+	}
+	if e.sensitive != nil {
+		c.sensitive = make(map[string]bool, len(e.sensitive))
+		for k, v := range e.sensitive {
+			c.sensitive[k] = v
+		}
+	}
+	if e.Retryable != nil {
+		retryable := *e.Retryable
+		c.Retryable = &retryable
+	}
+	c.Ops = append([]string(nil), e.Ops...)
+	c.Tags = append([]string(nil), e.Tags...)
+	c.Causes = append([]*Error(nil), e.Causes...)
+	c.Items = append([]BatchItem(nil), e.Items...)
+	c.ErrorDetails = append([]ErrorDetail(nil), e.ErrorDetails...)
+	c.stack = append([]uintptr(nil), e.stack...)
+	return &c
+}
+
+// cloneMap returns a shallow copy of m, or nil if m is nil.
+func cloneMap(m map[string]interface{}) map[string]interface{} {
+	if m == nil {
+		return nil
+	}
+	c := make(map[string]interface{}, len(m))
+	for k, v := range m {
+		c[k] = v
+	}
+	return c
+}
+
+// WithHint returns a copy of e with the developer-facing hint set, see Hint.
+//
+// Like the other With methods, it leaves e unchanged, so a shared error,
+// such as a package-level sentinel, can be annotated per request:
+//
+//	return ErrUserNotFound.WithHint("Pass ?email= to search by email.")
+func (e *Error) WithHint(hint string) *Error {
+	c := e.Clone()
+	c.Hint = hint
+	return c
+}
+
+// WithRetryable returns a copy of e with whether it is worth retrying set,
+// see IsRetryable.
+func (e *Error) WithRetryable(retryable bool) *Error {
+	c := e.Clone()
+	c.Retryable = &retryable
+	return c
+}
+
+// WithRemediation returns a copy of e with the end-user remediation set,
+// see Remediation.
+func (e *Error) WithRemediation(remediation string) *Error {
+	c := e.Clone()
+	c.Remediation = remediation
+	return c
+}
+
+// WithField returns a copy of e with the operator-only field key set to
+// value, see Fields.
+func (e *Error) WithField(key string, value interface{}) *Error {
+	c := e.Clone()
+	c.setField(key, value)
+	return c
+}
+
+// WithTags returns a copy of e with tags appended to its Tags.
+func (e *Error) WithTags(tags ...string) *Error {
+	c := e.Clone()
+	c.Tags = append(c.Tags, tags...)
+	return c
+}
+
+// WithLocation returns a copy of e with the URL path of the related
+// resource set, e.g. the existing resource of an EEXIST error. See
+// ResponseHeaders.
+func (e *Error) WithLocation(location string) *Error {
+	c := e.Clone()
+	c.Location = location
+	return c
+}
+
+// WithDetailText returns a copy of e with the human-readable elaboration of
+// its message set. Unlike Details, it is plain text meant for end users.
+func (e *Error) WithDetailText(detail string) *Error {
+	c := e.Clone()
+	c.Detail = detail
+	return c
+}
+
+// MarkInternal returns a copy of e with the given Details keys flagged as
+// internal: they are logged, see LogAttrs, but never sent to clients. The
+// keys may be marked on any error of the chain.
+//
+// All the Details of server errors (5xx) are internal, marked or not.
+func (e *Error) MarkInternal(keys ...string) *Error {
+	c := e.Clone()
+	if c.internal == nil {
+		c.internal = make(map[string]bool, len(keys))
+	}
+	for _, key := range keys {
+		c.internal[key] = true
+	}
+	return c
 }
 
 // Error method is used to return an error string suitable for operators.
@@ -65,32 +456,52 @@ func (e *Error) ResponseHeaders() (string, map[string]string) {
 // 2. Show Kind, Message at the end.
 // 3. Print on a single line so it's easy to grep.
 //
-// NOTE(truescotian): This implementation assumes that Err cannot coexist with Kind
-// or Message on any given error.
+// When Err coexists with Kind on a given error, the kind is printed before the
+// wrapped error so that errors of different kinds wrapping the same cause
+// don't print the same line. Message is not printed in that case.
+//
+// Like ops, a kind repeated by the wrapped error, as with WrapKind, is
+// printed once, so that the line stays readable.
+//
+// The Time isn't printed so that the line is the same for every occurrence,
+// see %+v and LogAttrs for the time of an occurrence.
 //
 // Error returns the string representation of the error message.
 func (e *Error) Error() string {
 	var buf bytes.Buffer
-
-	// Print the current operation in our stack, if any.
-	if e.Op != "" {
-		fmt.Fprintf(&buf, "%s: ", e.Op)
-	}
-
-	// If wrapping an error, print its Error() message.
-	// Otherwise print the error kind & message.
-	if e.Err != nil {
-		buf.WriteString(e.Err.Error())
-	} else {
-		if e.Kind != "" {
+	var lastOp, lastKind string
+	for depth := 0; depth < maxDepth; depth++ {
+		// Print the current operations in our stack, if any. Consecutive
+		// repeats of an op, as in recursive calls, are printed once.
+		for _, op := range e.layerOps() {
+			if op != lastOp {
+				fmt.Fprintf(&buf, "%s: ", op)
+				lastOp = op
+			}
+		}
+		if e.Kind != "" && e.Kind != lastKind {
 			fmt.Fprintf(&buf, "<%s> ", e.Kind)
+			lastKind = e.Kind
 		}
-		buf.WriteString(e.Message)
+
+		// If wrapping an error, print its Error() message.
+		// Otherwise print the error message.
+		next, ok := e.Err.(*Error)
+		if !ok {
+			if e.Err != nil {
+				buf.WriteString(e.Err.Error())
+			} else {
+				buf.WriteString(e.Message)
+			}
+			break
+		}
+		e = next
 	}
 	return buf.String()
 }
 
-// NewError returns an Error using the passed arguments.
+// NewError returns an Error using the passed arguments. The call stack is
+// captured for server errors, see SetCaptureStackFor.
 func NewError(op string, status int, message string, kind string, err error) *Error {
 	return &Error{
 		Op:      op,
@@ -98,7 +509,312 @@ func NewError(op string, status int, message string, kind string, err error) *Er
 		Message: message,
 		Kind:    kind,
 		Err:     err,
+		Time:    DefaultRegistry().now(),
+		stack:   DefaultRegistry().captureStack(kind, 1),
+	}
+}
+
+// NewAuto is like NewError but derives the Op from its caller, as the
+// package-qualified name of the calling function, e.g. a method CreateUser of
+// *UserService in package users gives "users.UserService.CreateUser". Unlike
+// a const op, the Op can't drift when the function is renamed.
+func NewAuto(status int, message string, kind string, err error) *Error {
+	return &Error{
+		Op:      callerOp(1),
+		Status:  status,
+		Message: message,
+		Kind:    kind,
+		Err:     err,
+		Time:    DefaultRegistry().now(),
+		stack:   DefaultRegistry().captureStack(kind, 1),
+	}
+}
+
+// callerOp returns the op of the caller of the function calling callerOp,
+// skipping skip frames above it, see NewAuto.
+func callerOp(skip int) string {
+	pc, _, _, ok := runtime.Caller(skip + 1)
+	if !ok {
+		return ""
+	}
+	fn := runtime.FuncForPC(pc)
+	if fn == nil {
+		return ""
+	}
+	name := fn.Name()
+	if i := strings.LastIndexByte(name, '/'); i >= 0 {
+		name = name[i+1:]
+	}
+	return strings.NewReplacer("(*", "", "(", "", ")", "").Replace(name)
+}
+
+// Wrap wraps err with op, so that it appears in the logical stack trace of
+// err. Returns nil if err is nil.
+//
+//	user, err := s.findUser(ctx, id)
+//	if err != nil {
+//		return nil, resterror.Wrap(err, "UserService.FindUserByID")
+//	}
+//
+// The call stack is captured if the kind of err requires it, see
+// SetCaptureStackFor.
+func Wrap(err error, op string) error {
+	if err == nil {
+		return nil
+	}
+	return &Error{Op: op, Err: err, Time: DefaultRegistry().now(), stack: DefaultRegistry().captureStack(ErrorKind(err), 1)}
+}
+
+// Wrapf is like Wrap but also adds the operator-facing context described by
+// format and args, which is printed before the message of err by Error.
+// Like the Op, the context is never sent to clients. Returns nil if err is
+// nil.
+func Wrapf(err error, op, format string, args ...interface{}) error {
+	if err == nil {
+		return nil
+	}
+	return &Error{
+		Op:    op,
+		Err:   fmt.Errorf("%s: %w", fmt.Sprintf(format, args...), err),
+		Time:  DefaultRegistry().now(),
+		stack: DefaultRegistry().captureStack(ErrorKind(err), 1),
+	}
+}
+
+// Errorf returns an error of kind whose Message is formatted like by
+// fmt.Errorf, and whose Err is the error of the %w verb, if any, so that
+// fmt.Errorf call sites can be migrated mechanically:
+//
+//	return resterror.Errorf(resterror.ENOTFOUND, "user %d: %w", id, err)
+//
+// Several %w verbs wrap all their errors, see errors.Join. The text of the
+// wrapped errors is left out of the Message, which is sent to clients for
+// client errors (4xx), see ClientSafeMessage; the example above has the
+// Message "user 42". It stays in the output of Error through Err.
+func Errorf(kind string, format string, args ...interface{}) *Error {
+	f := fmt.Errorf(format, args...)
+	var wrapped []error
+	switch u := f.(type) {
+	case interface{ Unwrap() error }:
+		wrapped = []error{u.Unwrap()}
+	case interface{ Unwrap() []error }:
+		wrapped = u.Unwrap()
+	}
+
+	msg := f.Error()
+	if len(wrapped) > 0 {
+		blanked := make([]interface{}, len(args))
+		for i, arg := range args {
+			blanked[i] = arg
+			if err, ok := arg.(error); ok && containsError(wrapped, err) {
+				blanked[i] = blankError{}
+			}
+		}
+		msg = strings.Trim(fmt.Errorf(format, blanked...).Error(), ": ")
+	}
+
+	e := &Error{Kind: kind, Message: msg, Time: DefaultRegistry().now(), stack: DefaultRegistry().captureStack(kind, 1)}
+	if len(wrapped) == 1 {
+		e.Err = wrapped[0]
+	} else if len(wrapped) > 1 {
+		e.Err = errors.Join(wrapped...)
+	}
+	return e
+}
+
+// blankError stands for a wrapped error when Errorf formats its Message.
+type blankError struct{}
+
+func (blankError) Error() string          { return "" }
+func (blankError) Format(fmt.State, rune) {}
+
+// containsError reports whether errs contains err, which isn't nil.
+func containsError(errs []error, err error) bool {
+	comparable := reflect.TypeOf(err).Comparable()
+	for _, e := range errs {
+		if comparable && e == err || !comparable && reflect.DeepEqual(e, err) {
+			return true
+		}
+	}
+	return false
+}
+
+// WrapKind wraps err with op, copying the resolved Kind and Status of err
+// to the new error so its own fields can be read without walking the chain.
+// The call stack is captured for server errors, see SetCaptureStackFor.
+// Returns nil if err is nil.
+func WrapKind(op string, err error) *Error {
+	if err == nil {
+		return nil
 	}
+	e := Coerce(err)
+	kind := ErrorKind(e)
+	return &Error{Op: op, Kind: kind, Status: ErrorStatus(e), Err: err, Time: DefaultRegistry().now(), stack: DefaultRegistry().captureStack(kind, 1)}
+}
+
+// Op runs fn and wraps the error it returns, if any, with the op name, for
+// quick instrumentation of a call site without a const op:
+//
+//	err := Op("UserService.CreateUser", func() error {
+//		return s.db.Insert(user)
+//	})
+//
+// Returns nil if fn succeeds.
+func Op(name string, fn func() error) error {
+	return Wrap(fn(), name)
+}
+
+// NewConflictError returns an ECONFLICT error: the action cannot be
+// performed in the current state of the resource.
+func NewConflictError(op, message string) *Error {
+	return NewError(op, http.StatusConflict, message, ECONFLICT, nil)
+}
+
+// NewExistsError returns an EEXIST error: the resource being created already
+// exists.
+func NewExistsError(op, message string) *Error {
+	return NewError(op, http.StatusConflict, message, EEXIST, nil)
+}
+
+// NewRangeError returns an EINVALID error for a param, such as a pagination
+// limit or offset, which is out of the range [min, max]. Details holds the
+// param and its range so clients can correct it.
+func NewRangeError(op, param string, min, max int) *Error {
+	e := NewError(op, http.StatusUnprocessableEntity, fmt.Sprintf("%s must be between %d and %d.", param, min, max), EINVALID, nil)
+	e.Details = map[string]interface{}{"param": param, "min": min, "max": max}
+	return e
+}
+
+// NewQuotaError returns an EQUOTA error: a limit of the plan of the client
+// was reached. Details holds the limit and the current usage so clients can
+// show them.
+//
+// The status is resolved from the kind, 429 by default. Register EQUOTA with
+// another status, such as 402, to change it, see RegisterKind.
+func NewQuotaError(op string, limit, used int) *Error {
+	e := NewError(op, 0, fmt.Sprintf("You have used %d of the %d allowed by your plan.", used, limit), EQUOTA, nil)
+	e.Details = map[string]interface{}{"limit": limit, "used": used}
+	return e
+}
+
+// NotFound returns an ENOTFOUND error with a 404 status: the resource does
+// not exist.
+func NotFound(op, message string) *Error {
+	return NewError(op, http.StatusNotFound, message, ENOTFOUND, nil)
+}
+
+// Invalid returns an EINVALID error with a 422 status: the request failed
+// validation.
+func Invalid(op, message string) *Error {
+	return NewError(op, http.StatusUnprocessableEntity, message, EINVALID, nil)
+}
+
+// Conflict returns an ECONFLICT error with a 409 status, like
+// NewConflictError.
+func Conflict(op, message string) *Error {
+	return NewError(op, http.StatusConflict, message, ECONFLICT, nil)
+}
+
+// Forbidden returns a PERMISSION error with a 403 status: the client isn't
+// allowed to perform the action.
+func Forbidden(op, message string) *Error {
+	return NewError(op, http.StatusForbidden, message, PERMISSION, nil)
+}
+
+// Internal returns an EINTERNAL error with a 500 status wrapping err. Its
+// cause is logged but never sent to the client, which gets the default
+// message, see ClientSafeMessage.
+func Internal(op string, err error) *Error {
+	return NewError(op, http.StatusInternalServerError, "", EINTERNAL, err)
+}
+
+// isKind reports whether err is non-nil and its kind is kind or one of its
+// descendants, see RegisterKindParent.
+func isKind(err error, kind string) bool {
+	return err != nil && DefaultRegistry().KindMatches(ErrorKind(err), kind)
+}
+
+// IsNotFound reports whether the kind of err is ENOTFOUND.
+// It doesn't match ENOTFOUND_ROUTE, which is reported by the router.
+func IsNotFound(err error) bool {
+	return isKind(err, ENOTFOUND)
+}
+
+// IsInvalid reports whether the kind of err is EINVALID.
+func IsInvalid(err error) bool {
+	return isKind(err, EINVALID)
+}
+
+// IsPermission reports whether the kind of err is PERMISSION.
+func IsPermission(err error) bool {
+	return isKind(err, PERMISSION)
+}
+
+// IsInternal reports whether the kind of err is EINTERNAL, which includes
+// errors without a kind, such as those of other packages, see ErrorKind.
+func IsInternal(err error) bool {
+	return isKind(err, EINTERNAL)
+}
+
+// IsConflict reports whether the kind of err is ECONFLICT.
+// It doesn't match EEXIST even though both have the same status.
+func IsConflict(err error) bool {
+	return isKind(err, ECONFLICT)
+}
+
+// IsExists reports whether the kind of err is EEXIST.
+// It doesn't match ECONFLICT even though both have the same status.
+func IsExists(err error) bool {
+	return isKind(err, EEXIST)
+}
+
+// IsQuota reports whether the kind of err is EQUOTA.
+func IsQuota(err error) bool {
+	return isKind(err, EQUOTA)
+}
+
+// AsError finds the first *Error in the chain of err, following any
+// standard library wrapping (fmt.Errorf with %w).
+//
+// For errors joined with errors.Join, the *Error of the highest-priority
+// branch is returned, see SetJoinPolicy.
+func AsError(err error) (*Error, bool) {
+	return DefaultRegistry().asError(err)
+}
+
+// asError is like AsError but resolves joined errors with the join policy of
+// r.
+func (r *Registry) asError(err error) (*Error, bool) {
+	if e, ok := r.joined(err); ok {
+		return e, true
+	}
+	return as[*Error](err)
+}
+
+// Coerce returns err as an *Error.
+//
+// If err is, or wraps, an *Error then that error is returned unchanged.
+// Errors and BatchErrors, wrapped or not, are returned as an *Error listing
+// their items, so their response lists every item. Otherwise err is of
+// unknown provenance and is wrapped as an internal error. Coerce returns nil
+// for nil errors.
+func Coerce(err error) *Error {
+	return DefaultRegistry().coerce(err)
+}
+
+// coerce is like Coerce but resolves joined errors with the join policy of
+// r.
+func (r *Registry) coerce(err error) *Error {
+	if err == nil {
+		return nil
+	} else if es, ok := err.(Errors); ok {
+		return es.asError()
+	} else if b, ok := wrappedBatch(err); ok {
+		return &Error{Err: err, Items: b.Items}
+	} else if e, ok := r.asError(err); ok {
+		return e
+	}
+	return &Error{Kind: EINTERNAL, Status: http.StatusInternalServerError, Err: err}
 }
 
 // ErrorKind returns the kind of the root error if available.
@@ -110,17 +826,21 @@ func NewError(op string, status int, message string, kind string, err error) *Er
 // issues are solved by the following:
 //
 // 1. Return no error kind for nil errors.
-// 2. Search the chain of Error.Err until a defined Kind is found.
+// 2. Search the chain of err until a defined Kind is found, following both
+// Error.Err and standard library wrappers (fmt.Errorf with %w).
 // 3. If no kind is defined then return an internal error kind (EINTERNAL).
 func ErrorKind(err error) string {
+	return DefaultRegistry().errorKind(err)
+}
+
+// errorKind is like ErrorKind but resolves joined errors with the join
+// policy of r.
+func (r *Registry) errorKind(err error) string {
 	if err == nil {
 		return ""
-	} else if e, ok := err.(*Error); ok && e.Kind != "" {
+	} else if e, ok := r.lookup(err, func(e *Error) bool { return e.Kind != "" }); ok {
 		return e.Kind
-	} else if ok && e.Err != nil {
-		return ErrorKind(e.Err)
 	}
-
 	return EINTERNAL
 }
 
@@ -130,36 +850,163 @@ func ErrorKind(err error) string {
 // This is similar to ErrorKind except for the following rules:
 //
 // 1. Returns no error message for nil errors.
-// 2. Searches the chain of Error.Err until a defined Message is found.
-// 3. If no message is defined then return a generic error message.
+// 2. Searches the chain of err until a defined Message is found, like
+// ErrorKind.
+// 3. If no message is defined then return the message of the error kind,
+// or a generic error message, see RegisterKind and SetDefaultMessage.
 //
 // Returns the human-readable message of the error, if available.
 // Otherwise returns a generic error message.
 func ErrorMessage(err error) string {
-	if err == nil {
-		return ""
-	} else if e, ok := err.(*Error); ok && e.Message != "" {
-		return e.Message
-	} else if ok && e.Err != nil {
-		return ErrorMessage(e.Err)
+	return DefaultRegistry().ErrorMessage(err)
+}
+
+// SameKind reports whether a and b resolve to the same kind, see ErrorKind.
+// Two nil errors are of the same kind, a nil and a non-nil error are not.
+func SameKind(a, b error) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
 	}
-	return "An internal error has occurred. Please contact technical support."
+	return ErrorKind(a) == ErrorKind(b)
 }
 
-// Is reports whether err is an *Error of the given Kind.
+// Is reports whether err is an *Error of the given Kind, or of a kind
+// descending from it, see RegisterKindParent.
 // If err is nil then Is returns false.
 //
 // Source: https://upspin.googlesource.com/upspin/+/033a63d02f07/errors/errors.go#484
 func Is(kind string, err error) bool {
-	e, ok := err.(*Error)
+	if e, ok := lookup(err, func(e *Error) bool { return e.Kind != "" && e.Kind != OTHER }); ok {
+		return DefaultRegistry().KindMatches(e.Kind, kind)
+	}
+	return false
+}
+
+// maxDepth caps the number of layers walked by the utility functions, so that
+// an accidentally self-referential chain (e.Err = e) can't hang or overflow
+// the stack. Chains deeper than that are treated as if they ended there.
+const maxDepth = 100
+
+// lookup returns the first *Error in the chain of err for which fn returns
+// true, resolving joined errors with the default registry, see
+// Registry.lookup.
+func lookup(err error, fn func(*Error) bool) (*Error, bool) {
+	return DefaultRegistry().lookup(err, fn)
+}
+
+// lookup returns the first *Error in the chain of err for which fn returns
+// true, searching at most maxDepth layers. Other wrappers, such as those of
+// fmt.Errorf with %w, are followed through errors.Unwrap. Joined errors are
+// searched through their highest-priority branch, see joined.
+func (r *Registry) lookup(err error, fn func(*Error) bool) (*Error, bool) {
+	for depth := 0; err != nil && depth < maxDepth; depth++ {
+		e, ok := err.(*Error)
+		if !ok {
+			if e, ok = r.joined(err); !ok {
+				err = errors.Unwrap(err)
+				continue
+			}
+		}
+		if fn(e) {
+			return e, true
+		}
+		err = e.Err
+	}
+	return nil, false
+}
+
+// JoinPolicy decides which branch of a joined error, such as those of
+// errors.Join, the helpers of this package resolve the kind, status and
+// message from. See SetJoinPolicy.
+type JoinPolicy int
+
+const (
+	// JoinHighestStatus picks the branch with the highest class of status,
+	// so that a server error takes precedence over a client error, since it
+	// needs to be reported. Between branches of the same class the first one
+	// wins. It is the default.
+	JoinHighestStatus JoinPolicy = iota
+
+	// JoinFirst picks the first branch holding an *Error.
+	JoinFirst
+)
+
+// joined returns the *Error of the highest-priority branch of err, if err is
+// a multi-error such as those of errors.Join, according to the join policy
+// of r. Branches without an *Error are ignored.
+func (r *Registry) joined(err error) (*Error, bool) {
+	multi, ok := err.(interface{ Unwrap() []error })
 	if !ok {
-		return false
+		return nil, false
 	}
-	if e.Kind != OTHER {
-		return e.Kind == kind
+
+	var best *Error
+	bestClass := 0
+	for _, branch := range multi.Unwrap() {
+		e, ok := r.asError(branch)
+		if !ok {
+			continue
+		}
+		if r.joinPolicy == JoinFirst {
+			return e, true
+		}
+		if class := r.ErrorStatus(e) / 100; best == nil || class > bestClass {
+			best, bestClass = e, class
+		}
 	}
-	if e.Err != nil {
-		return Is(kind, e.Err)
+	return best, best != nil
+}
+
+// Walk calls fn for each *Error in the chain of err, outermost first, until
+// fn returns false or the chain ends.
+//
+// Standard library wrappers (fmt.Errorf with %w) between our errors are
+// followed but not visited. Joined errors are walked through their
+// highest-priority branch, see SetJoinPolicy.
+func Walk(err error, fn func(*Error) bool) {
+	DefaultRegistry().walk(err, fn)
+}
+
+// walk is like Walk but resolves joined errors with the join policy of r.
+func (r *Registry) walk(err error, fn func(*Error) bool) {
+	for depth := 0; err != nil && depth < maxDepth; depth++ {
+		e, ok := err.(*Error)
+		if !ok {
+			if e, ok = r.joined(err); !ok {
+				err = errors.Unwrap(err)
+				continue
+			}
+		}
+		if !fn(e) {
+			return
+		}
+		err = e.Err
 	}
-	return false
+}
+
+// GetOp returns the Op of the outermost *Error of err, if any.
+//
+// Unlike ErrorKind or ErrorMessage the chain isn't searched for a defined
+// value, the accessors only read the fields of the outermost *Error.
+func GetOp(err error) string {
+	if e, ok := AsError(err); ok {
+		return e.Op
+	}
+	return ""
+}
+
+// GetMessage returns the Message of the outermost *Error of err, if any.
+func GetMessage(err error) string {
+	if e, ok := AsError(err); ok {
+		return e.Message
+	}
+	return ""
+}
+
+// GetStatus returns the Status of the outermost *Error of err, if any.
+func GetStatus(err error) int {
+	if e, ok := AsError(err); ok {
+		return e.Status
+	}
+	return 0
 }
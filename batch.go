@@ -0,0 +1,157 @@
+package error
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// BatchError collects the errors of the items of a bulk operation, by index
+// and optionally by ID, so the client knows which items failed and why:
+//
+//	var batch resterror.BatchError
+//	for i, u := range users {
+//		batch.AddID(i, u.Email, s.CreateUser(ctx, u))
+//	}
+//	return batch.ErrorOrNil()
+//
+// Its response takes its Kind, Status and Message from the item with the
+// highest status class, like Errors, and lists the items:
+//
+//	{"kind":"invalid",...,"items":[{"index":3,"id":"a@example.com","kind":"invalid","message":"Email is invalid."}]}
+type BatchError struct {
+	Items []BatchItem
+}
+
+var _ ClientError = (*BatchError)(nil)
+
+// BatchItem is the error of an item of a bulk operation. It is serialized
+// as its Index, ID, and the kind and client-safe message of Err. Items
+// without an Err didn't fail, they are skipped.
+type BatchItem struct {
+	Index int
+	ID    string
+	Err   *Error
+}
+
+// MarshalJSON returns the JSON encoding of i. Err must be a public copy,
+// see Error.Public.
+func (i BatchItem) MarshalJSON() ([]byte, error) {
+	var kind, message string
+	if i.Err != nil {
+		kind, message = i.Err.Kind, i.Err.Message
+	}
+	return json.Marshal(struct {
+		Index   int    `json:"index"`
+		ID      string `json:"id,omitempty"`
+		Kind    string `json:"kind"`
+		Message string `json:"message"`
+	}{i.Index, i.ID, kind, message})
+}
+
+// Add records err as the error of the item at index. Nil errors are
+// skipped, and errors which aren't an *Error are coerced to one, see Coerce.
+func (b *BatchError) Add(index int, err error) {
+	b.AddID(index, "", err)
+}
+
+// AddID is like Add for an item which also has an ID, e.g. the key of the
+// item in the request.
+func (b *BatchError) AddID(index int, id string, err error) {
+	if err == nil {
+		return
+	}
+	b.Items = append(b.Items, BatchItem{Index: index, ID: id, Err: Coerce(err)})
+}
+
+// ErrorOrNil returns b as an error, or nil if no item failed.
+func (b *BatchError) ErrorOrNil() error {
+	if len(b.Items) == 0 {
+		return nil
+	}
+	return b
+}
+
+// Error returns the errors of the items of b, prefixed by their index,
+// separated by semicolons.
+func (b *BatchError) Error() string {
+	var msgs []string
+	for _, item := range b.Items {
+		if item.Err != nil {
+			msgs = append(msgs, fmt.Sprintf("item %d: %v", item.Index, item.Err))
+		}
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// Unwrap returns the errors of the items of b, so errors.Is and errors.As
+// match any of them, and the helpers of this package resolve the item with
+// the highest status class.
+func (b *BatchError) Unwrap() []error {
+	var errs []error
+	for _, item := range b.Items {
+		if item.Err != nil {
+			errs = append(errs, item.Err)
+		}
+	}
+	return errs
+}
+
+// ResponseBody returns the response body of b, listing its items.
+func (b *BatchError) ResponseBody() ([]byte, error) {
+	return b.asError().ResponseBody()
+}
+
+// ResponseHeaders returns the HTTP status code of b, that of the item with
+// the highest status class, and the headers of the response.
+func (b *BatchError) ResponseHeaders() (int, map[string]string) {
+	return b.asError().ResponseHeaders()
+}
+
+// asError returns b as an *Error listing its items.
+func (b *BatchError) asError() *Error {
+	return &Error{Err: b, Items: b.Items}
+}
+
+// wrappedBatch returns the *BatchError in the chain of err, unless an *Error
+// comes before it, which AsError returns instead.
+func wrappedBatch(err error) (*BatchError, bool) {
+	var b *BatchError
+	visit(err, func(err error) bool {
+		switch err := err.(type) {
+		case *Error:
+			return true
+		case *BatchError:
+			b = err
+			return true
+		}
+		return false
+	})
+	return b, b != nil
+}
+
+// publicItems returns the first Items found in the chain of err, those of an
+// *Error or of a wrapped *BatchError, with the public copies of their errors.
+func (r *Registry) publicItems(err error) []BatchItem {
+	var found []BatchItem
+	visit(err, func(err error) bool {
+		switch err := err.(type) {
+		case *Error:
+			found = err.Items
+		case *BatchError:
+			found = err.Items
+		}
+		return len(found) != 0
+	})
+	if len(found) == 0 {
+		return nil
+	}
+
+	var items []BatchItem
+	for _, item := range found {
+		if item.Err != nil {
+			items = append(items, BatchItem{Index: item.Index, ID: item.ID, Err: r.Public(item.Err)})
+		}
+	}
+	return items
+}
@@ -0,0 +1,103 @@
+package error_test
+
+import (
+	"errors"
+	"fmt"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	resterror "github.com/truescotian/resterror"
+)
+
+func TestBatchError(t *testing.T) {
+	var batch resterror.BatchError
+	if err := batch.ErrorOrNil(); err != nil {
+		t.Fatalf("ErrorOrNil()=%v, want nil", err)
+	}
+
+	invalid := resterror.Invalid("CreateUser", "Email is invalid.")
+	batch.AddID(0, "a@example.com", nil)
+	batch.AddID(3, "b@example", invalid)
+	batch.Add(5, resterror.NewExistsError("CreateUser", "Username is already in use."))
+	err := batch.ErrorOrNil()
+
+	if !errors.Is(err, invalid) {
+		t.Fatal("errors.Is should match an item")
+	}
+	if got, want := err.Error(), "item 3: CreateUser: <invalid> Email is invalid.; item 5: CreateUser: <item_already_exists> Username is already in use."; got != want {
+		t.Fatalf("Error()=%q, want %q", got, want)
+	}
+
+	w := httptest.NewRecorder()
+	resterror.WriteError(w, err)
+	if w.Code != 422 {
+		t.Fatalf("status=%d, want 422", w.Code)
+	}
+	want := `{"kind":"invalid","status":422,"message":"Email is invalid.","items":[` +
+		`{"index":3,"id":"b@example","kind":"invalid","message":"Email is invalid."},` +
+		`{"index":5,"kind":"item_already_exists","message":"Username is already in use."}]}`
+	if got := w.Body.String(); got != want {
+		t.Fatalf("body=%s, want %s", got, want)
+	}
+}
+
+func TestBatchError_ServerError(t *testing.T) {
+	var batch resterror.BatchError
+	batch.Add(1, errors.New("pq: deadlock detected"))
+	body, err := batch.ResponseBody()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"kind":"internal","status":500,"message":"An internal error has occurred. Please contact technical support.","items":[` +
+		`{"index":1,"kind":"internal","message":"An internal error has occurred. Please contact technical support."}]}`
+	if string(body) != want {
+		t.Fatalf("body=%s, want %s", body, want)
+	}
+}
+
+func TestBatchError_Wrapped(t *testing.T) {
+	var batch resterror.BatchError
+	batch.AddID(3, "b@example", resterror.Invalid("CreateUser", "Email is invalid."))
+	items := `"items":[{"index":3,"id":"b@example","kind":"invalid","message":"Email is invalid."}]`
+
+	for _, err := range []error{
+		&resterror.Error{Op: "BulkCreate", Err: &batch},
+		resterror.E("BulkCreate", &batch),
+		fmt.Errorf("bulk: %w", &batch),
+	} {
+		w := httptest.NewRecorder()
+		resterror.WriteError(w, err)
+		if want := `{"kind":"invalid","status":422,"message":"Email is invalid.",` + items + `}`; w.Code != 422 || w.Body.String() != want {
+			t.Errorf("%v: status=%d body=%s, want %s", err, w.Code, w.Body, want)
+		}
+
+		w = httptest.NewRecorder()
+		resterror.WriteProblem(w, err)
+		if !strings.Contains(w.Body.String(), items) {
+			t.Errorf("%v: problem=%s, want %s", err, w.Body, items)
+		}
+	}
+}
+
+func TestBatchError_NilItemErr(t *testing.T) {
+	batch := &resterror.BatchError{Items: []resterror.BatchItem{
+		{Index: 1},
+		{Index: 2, Err: resterror.Invalid("CreateUser", "Email is invalid.")},
+	}}
+	body, err := batch.ResponseBody()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"kind":"invalid","status":422,"message":"Email is invalid.","items":[{"index":2,"kind":"invalid","message":"Email is invalid."}]}`
+	if string(body) != want {
+		t.Fatalf("body=%s, want %s", body, want)
+	}
+	if got, want := batch.Error(), "item 2: CreateUser: <invalid> Email is invalid."; got != want {
+		t.Fatalf("Error()=%q, want %q", got, want)
+	}
+
+	if _, err := (&resterror.BatchError{Items: []resterror.BatchItem{{Index: 1}}}).ResponseBody(); err != nil {
+		t.Fatal(err)
+	}
+}
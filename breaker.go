@@ -0,0 +1,23 @@
+package error
+
+import (
+	"context"
+	"net"
+)
+
+// ShouldTripBreaker reports whether err should count as a failure for a
+// circuit breaker guarding a remote call.
+//
+// Only server errors (5xx) and transport failures, such as timeouts or reset
+// connections, trip the breaker. Client errors (4xx) are the caller's
+// mistake and say nothing about the health of the remote end.
+func ShouldTripBreaker(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	if _, ok := as[net.Error](err); ok || is(err, context.DeadlineExceeded) {
+		return true
+	}
+	return ErrorStatus(Coerce(err)) >= 500
+}
@@ -0,0 +1,33 @@
+package error_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"syscall"
+	"testing"
+
+	resterror "github.com/truescotian/resterror"
+)
+
+func TestShouldTripBreaker(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"nil", nil, false},
+		{"internal", &resterror.Error{Kind: resterror.EINTERNAL, Status: 500}, true},
+		{"not found", &resterror.Error{Kind: resterror.ENOTFOUND, Status: 404}, false},
+		{"wrapped not found", fmt.Errorf("fetch: %w", &resterror.Error{Kind: resterror.ENOTFOUND, Status: 404}), false},
+		{"deadline exceeded", context.DeadlineExceeded, true},
+		{"op error", &net.OpError{Op: "read", Net: "tcp", Err: syscall.ECONNRESET}, true},
+		{"plain", errors.New("boom"), true},
+	}
+	for _, tt := range tests {
+		if got := resterror.ShouldTripBreaker(tt.err); got != tt.want {
+			t.Errorf("%s: ShouldTripBreaker()=%v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,74 @@
+package error
+
+// Builder builds an *Error fluently, so that partially populated errors read
+// clearly at call sites:
+//
+//	return resterror.New("UserService.CreateUser").
+//		Kind(resterror.EINVALID).
+//		Status(http.StatusUnprocessableEntity).
+//		Msg("Username is required.").
+//		Build()
+//
+// A Builder is terminated by Build, or by Wrap to wrap a cause.
+type Builder struct {
+	e Error
+}
+
+// New returns a Builder of an *Error of op.
+func New(op string) *Builder {
+	return &Builder{e: Error{Op: op}}
+}
+
+// Kind sets the Kind of the error.
+func (b *Builder) Kind(kind string) *Builder {
+	b.e.Kind = kind
+	return b
+}
+
+// Status sets the HTTP status code of the error.
+func (b *Builder) Status(status int) *Builder {
+	b.e.Status = status
+	return b
+}
+
+// Msg sets the Message of the error.
+func (b *Builder) Msg(message string) *Builder {
+	b.e.Message = message
+	return b
+}
+
+// Hint sets the developer-facing Hint of the error.
+func (b *Builder) Hint(hint string) *Builder {
+	b.e.Hint = hint
+	return b
+}
+
+// Remediation sets the end-user Remediation of the error.
+func (b *Builder) Remediation(remediation string) *Builder {
+	b.e.Remediation = remediation
+	return b
+}
+
+// Field sets the operator-only field key of the error, see Error.Fields.
+func (b *Builder) Field(key string, value interface{}) *Builder {
+	b.e = *b.e.WithField(key, value)
+	return b
+}
+
+// Build returns the error. The call stack is captured like by NewError.
+func (b *Builder) Build() *Error {
+	e := b.e
+	e.Time = DefaultRegistry().now()
+	e.stack = DefaultRegistry().captureStack(e.Kind, 1)
+	return &e
+}
+
+// Wrap returns the error wrapping err. The call stack is captured like by
+// NewError.
+func (b *Builder) Wrap(err error) *Error {
+	e := b.e
+	e.Err = err
+	e.Time = DefaultRegistry().now()
+	e.stack = DefaultRegistry().captureStack(e.Kind, 1)
+	return &e
+}
@@ -0,0 +1,46 @@
+package error_test
+
+import (
+	"errors"
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+
+	resterror "github.com/truescotian/resterror"
+)
+
+func TestBuilder(t *testing.T) {
+	defer resterror.SetDefaultRegistry(resterror.DefaultRegistry())
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	resterror.SetClock(func() time.Time { return now })
+
+	e := resterror.New("UserService.CreateUser").
+		Kind(resterror.EINVALID).
+		Status(http.StatusUnprocessableEntity).
+		Msg("Username is required.").
+		Hint("Pass a non-empty username.").
+		Field("org_id", 7).
+		Build()
+	want := &resterror.Error{
+		Op:      "UserService.CreateUser",
+		Kind:    resterror.EINVALID,
+		Status:  http.StatusUnprocessableEntity,
+		Message: "Username is required.",
+		Hint:    "Pass a non-empty username.",
+		Fields:  map[string]interface{}{"org_id": 7},
+		Time:    now,
+	}
+	if !reflect.DeepEqual(e, want) {
+		t.Fatalf("Build()=%#v", e)
+	}
+
+	cause := errors.New("dial tcp: connection refused")
+	e = resterror.New("UserService.FindUser").Kind(resterror.EINTERNAL).Wrap(cause)
+	if e.Op != "UserService.FindUser" || e.Kind != resterror.EINTERNAL || !errors.Is(e, cause) {
+		t.Fatalf("Wrap()=%#v", e)
+	}
+	if len(e.Frames()) == 0 {
+		t.Fatal("no frames captured for an internal error")
+	}
+}
@@ -0,0 +1,47 @@
+package error
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+)
+
+// CatalogEntry describes a kind in the error catalog.
+type CatalogEntry struct {
+	Kind    string `json:"kind"`
+	Code    int    `json:"code,omitempty"`
+	Status  int    `json:"status"`
+	Message string `json:"message"`
+}
+
+// Catalog returns every kind registered in r, builtin or not, with its
+// default status and message, sorted by kind.
+func (r *Registry) Catalog() []CatalogEntry {
+	catalog := make([]CatalogEntry, 0, len(r.kinds))
+	for kind := range r.kinds {
+		catalog = append(catalog, CatalogEntry{
+			Kind:    kind,
+			Code:    r.CodeForKind(kind),
+			Status:  r.StatusForKind(kind),
+			Message: r.MessageForKind(kind),
+		})
+	}
+	sort.Slice(catalog, func(i, j int) bool { return catalog[i].Kind < catalog[j].Kind })
+	return catalog
+}
+
+// CatalogHandler returns a handler serving the catalog of the default
+// registry as JSON, e.g. on /errors, so client SDKs and docs can discover
+// the errors of the API. Kinds registered at runtime are included.
+func CatalogHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := json.Marshal(DefaultRegistry().Catalog())
+		if err != nil {
+			WriteError(w, err)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		w.Header().Set("X-Content-Type-Options", "nosniff")
+		w.Write(body)
+	})
+}
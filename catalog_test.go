@@ -0,0 +1,36 @@
+package error_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	resterror "github.com/truescotian/resterror"
+)
+
+func TestCatalogHandler(t *testing.T) {
+	defer resterror.SetDefaultRegistry(resterror.DefaultRegistry())
+	resterror.RegisterKind("payment_required", http.StatusPaymentRequired, "Your plan has expired.")
+
+	rec := httptest.NewRecorder()
+	resterror.CatalogHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/errors", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status=%d", rec.Code)
+	}
+
+	var catalog []resterror.CatalogEntry
+	if err := json.Unmarshal(rec.Body.Bytes(), &catalog); err != nil {
+		t.Fatal(err)
+	}
+	entries := map[string]resterror.CatalogEntry{}
+	for _, e := range catalog {
+		entries[e.Kind] = e
+	}
+	if e := entries[resterror.ENOTFOUND]; e.Status != http.StatusNotFound || e.Message != resterror.MsgNotFound {
+		t.Fatalf("ENOTFOUND entry=%+v", e)
+	}
+	if e := entries["payment_required"]; e.Status != http.StatusPaymentRequired || e.Message != "Your plan has expired." {
+		t.Fatalf("payment_required entry=%+v", e)
+	}
+}
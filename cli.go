@@ -0,0 +1,49 @@
+package error
+
+import (
+	"fmt"
+	"io"
+)
+
+// exitCodes maps kinds to the exit code of command-line tools, see
+// FprintCLI. Other kinds exit with 1.
+var exitCodes = map[string]int{
+	EINVALID:        2,
+	EPARSE:          2,
+	ENOTFOUND:       3,
+	ENOTFOUND_ROUTE: 3,
+	PERMISSION:      4,
+	ECONFLICT:       5,
+	EEXIST:          5,
+}
+
+// FprintCLI prints err, with its logical stack trace, to w and returns the
+// exit code of its kind, for command-line tools:
+//
+//	if err := run(); err != nil {
+//		os.Exit(resterror.FprintCLI(os.Stderr, err))
+//	}
+//
+// Invalid input exits with 2, missing items with 3, permission errors with 4,
+// conflicts with 5, and any other error with 1. Nothing is printed and 0 is
+// returned for a nil error.
+func FprintCLI(w io.Writer, err error) int {
+	return fprintCLI(w, err, "error: %v\n")
+}
+
+// FprintCLIVerbose is like FprintCLI but prints err with %+v, for the verbose
+// mode of command-line tools.
+func FprintCLIVerbose(w io.Writer, err error) int {
+	return fprintCLI(w, err, "error: %+v\n")
+}
+
+func fprintCLI(w io.Writer, err error, format string) int {
+	if err == nil {
+		return 0
+	}
+	fmt.Fprintf(w, format, err)
+	if code, ok := exitCodes[ErrorKind(err)]; ok {
+		return code
+	}
+	return 1
+}
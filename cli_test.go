@@ -0,0 +1,34 @@
+package error_test
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	resterror "github.com/truescotian/resterror"
+)
+
+func TestFprintCLI(t *testing.T) {
+	tests := []struct {
+		err  error
+		code int
+		out  string
+	}{
+		{nil, 0, ""},
+		{&resterror.Error{Op: "ParseFlags", Kind: resterror.EINVALID, Message: "--limit must be positive."}, 2, "error: ParseFlags: <invalid> --limit must be positive.\n"},
+		{&resterror.Error{Op: "Sync", Err: &resterror.Error{Op: "FindUser", Kind: resterror.ENOTFOUND, Message: "User not found."}}, 3, "error: Sync: FindUser: <item_does_not_exist> User not found.\n"},
+		{&resterror.Error{Kind: resterror.PERMISSION, Message: "Not allowed."}, 4, "error: <permission> Not allowed.\n"},
+		{&resterror.Error{Kind: resterror.EEXIST, Message: "Already exists."}, 5, "error: <item_already_exists> Already exists.\n"},
+		{&resterror.Error{Op: "Sync", Kind: resterror.EINTERNAL, Err: errors.New("dial tcp: connection refused")}, 1, "error: Sync: <internal> dial tcp: connection refused\n"},
+		{errors.New("boom"), 1, "error: boom\n"},
+	}
+	for _, tt := range tests {
+		var buf bytes.Buffer
+		if code := resterror.FprintCLI(&buf, tt.err); code != tt.code {
+			t.Fatalf("FprintCLI(%v)=%d, want %d", tt.err, code, tt.code)
+		}
+		if buf.String() != tt.out {
+			t.Fatalf("output=%q, want %q", buf.String(), tt.out)
+		}
+	}
+}
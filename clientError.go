@@ -1,5 +1,11 @@
 package error
 
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
 // ClientError is one of the two main error types (Client Error for 4xx and
 // Server Error for 5xx). Here we can declare interfaces based on the behaviour
 // we expect from these two types and use type assertion on rootHandler
@@ -17,3 +23,209 @@ type ClientError interface {
 	// (content-type, no-cache).
 	ResponseHeaders() (int, map[string]string)
 }
+
+var _ ClientError = (*Error)(nil)
+
+// AssertClientError returns nil if err is nil or a ClientError, such as an
+// *Error, possibly wrapped. Otherwise it returns an error naming the type of
+// err, which would reach clients as a generic internal error.
+//
+// It is meant for tests asserting that handlers only return errors of ours,
+// see also Handler.Strict.
+func AssertClientError(err error) error {
+	if _, ok := as[ClientError](err); err == nil || ok {
+		return nil
+	}
+	return fmt.Errorf("unhandled error type %T reached the client: %v", err, err)
+}
+
+// Public returns a shallow copy of e which is safe to send to an untrusted
+// client. Only Kind, Code, Status, a client-safe Message, Detail, Hint,
+// Remediation, DocsURL, a copy of Details, the ErrorDetails, the IncidentID
+// and the public copies of Causes and Items are populated, the operator-only
+// Op and Err fields are left unset so the logical stack trace and the wrapped
+// causes never escape.
+//
+// Kind and Status are resolved through the chain, so a wrapping error without
+// a Kind of its own still reports the kind of its root.
+func (e *Error) Public() *Error {
+	return DefaultRegistry().Public(e)
+}
+
+// Public is like Error.Public but resolves e with r.
+func (r *Registry) Public(e *Error) *Error {
+	if e == nil {
+		return nil
+	}
+	pub := r.public(e)
+	return &pub
+}
+
+// public returns the client-safe copy of e by value, see Public.
+func (r *Registry) public(e *Error) Error {
+	return Error{
+		Kind:         r.errorKind(e),
+		Code:         r.errorCode(e),
+		Status:       r.ErrorStatus(e),
+		Message:      r.truncate(r.ClientSafeMessage(e)),
+		Detail:       r.errorDetail(e),
+		Hint:         r.errorHint(e),
+		Remediation:  r.errorRemediation(e),
+		DocsURL:      r.docsURL(e),
+		Details:      r.publicDetails(e),
+		ErrorDetails: r.errorDetails(e),
+		IncidentID:   r.errorIncidentID(e),
+		Causes:       r.publicCauses(e),
+		Items:        r.publicItems(e),
+	}
+}
+
+// ClientSafeMessage returns a message which can be shown to an end user.
+//
+// The first UserMessage found in the chain is returned if there is one.
+// Otherwise, messages of client errors (4xx) are returned as is, see
+// ErrorMessage. Server errors (5xx) may carry details about our system, such
+// as a query or a schema, so the default message is returned for them
+// instead, see SetDefaultMessage, unless the Message of an error of the chain
+// was vetted with AllowMessage, in which case that Message is returned.
+func ClientSafeMessage(err error) string {
+	return DefaultRegistry().ClientSafeMessage(err)
+}
+
+// ClientSafeMessage is like the package-level ClientSafeMessage but resolves
+// err with r.
+func (r *Registry) ClientSafeMessage(err error) string {
+	if err == nil {
+		return ""
+	} else if e, ok := r.lookup(err, func(e *Error) bool { return e.UserMessage != "" }); ok {
+		return e.UserMessage
+	}
+	if r.ErrorStatus(err) >= 500 {
+		if e, ok := r.lookup(err, func(e *Error) bool { return e.AllowMessage && hasMessage(e) }); ok {
+			return r.message(e)
+		}
+		return r.defaultMessage
+	}
+	return r.ErrorMessage(err)
+}
+
+// ErrorStatus returns the HTTP status code of err, analogous to ErrorKind:
+// the first non-zero Status found in the chain of err, following both
+// Error.Err and standard library wrappers. Otherwise returns the status of
+// the kind of err, which is 500 for errors without a kind.
+// Returns 0 for nil errors.
+func ErrorStatus(err error) int {
+	return DefaultRegistry().ErrorStatus(err)
+}
+
+// ErrorStatus is like the package-level ErrorStatus but resolves err with
+// r.
+func (r *Registry) ErrorStatus(err error) int {
+	if err == nil {
+		return 0
+	} else if e, ok := r.lookup(err, func(e *Error) bool { return e.Status != 0 }); ok {
+		return e.Status
+	}
+	return r.StatusForKind(r.errorKind(err))
+}
+
+// errorCode returns the first non-zero Code found in the chain of Error.Err.
+// Otherwise returns the code of the error kind.
+func (r *Registry) errorCode(err error) int {
+	if e, ok := r.lookup(err, func(e *Error) bool { return e.Code != 0 }); ok {
+		return e.Code
+	}
+	return r.CodeForKind(r.errorKind(err))
+}
+
+// errorDetail returns the first Detail found in the chain of Error.Err.
+func (r *Registry) errorDetail(err error) string {
+	if e, ok := r.lookup(err, func(e *Error) bool { return e.Detail != "" }); ok {
+		return e.Detail
+	}
+	return ""
+}
+
+// errorIncidentID returns the first IncidentID found in the chain of
+// Error.Err.
+func (r *Registry) errorIncidentID(err error) string {
+	if e, ok := r.lookup(err, func(e *Error) bool { return e.IncidentID != "" }); ok {
+		return e.IncidentID
+	}
+	return ""
+}
+
+// errorHint returns the first Hint found in the chain of Error.Err.
+func (r *Registry) errorHint(err error) string {
+	if e, ok := r.lookup(err, func(e *Error) bool { return e.Hint != "" }); ok {
+		return e.Hint
+	}
+	return ""
+}
+
+// errorRemediation returns the first Remediation found in the chain of
+// Error.Err.
+func (r *Registry) errorRemediation(err error) string {
+	if e, ok := r.lookup(err, func(e *Error) bool { return e.Remediation != "" }); ok {
+		return e.Remediation
+	}
+	return ""
+}
+
+// docsURL returns the first DocsURL found in the chain of Error.Err, or the
+// page of the kind of err under the docs base URL of r, if any.
+func (r *Registry) docsURL(err error) string {
+	if e, ok := r.lookup(err, func(e *Error) bool { return e.DocsURL != "" }); ok {
+		return e.DocsURL
+	} else if r.docsBaseURL == "" {
+		return ""
+	}
+	return strings.TrimSuffix(r.docsBaseURL, "/") + "/" + url.PathEscape(r.errorKind(err))
+}
+
+// publicDetails returns a copy of the first Details found in the chain of
+// Error.Err, without the keys marked internal. Server errors have no public
+// details, see MarkInternal.
+func (r *Registry) publicDetails(err error) map[string]interface{} {
+	e, ok := r.lookup(err, func(e *Error) bool { return len(e.Details) != 0 })
+	if !ok || r.ErrorStatus(err) >= 500 {
+		return nil
+	}
+
+	var internal map[string]bool
+	r.walk(err, func(e *Error) bool {
+		for key := range e.internal {
+			if internal == nil {
+				internal = make(map[string]bool)
+			}
+			internal[key] = true
+		}
+		return true
+	})
+
+	details := make(map[string]interface{}, len(e.Details))
+	for k, v := range e.Details {
+		if !internal[k] {
+			details[k] = v
+		}
+	}
+	if len(details) == 0 {
+		return nil
+	}
+	return details
+}
+
+// publicCauses returns the public copies of the first Causes found in the
+// chain of Error.Err.
+func (r *Registry) publicCauses(err error) []*Error {
+	e, ok := r.lookup(err, func(e *Error) bool { return len(e.Causes) != 0 })
+	if !ok {
+		return nil
+	}
+
+	causes := make([]*Error, len(e.Causes))
+	for i, c := range e.Causes {
+		causes[i] = r.Public(c)
+	}
+	return causes
+}
@@ -0,0 +1,275 @@
+package error_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	resterror "github.com/truescotian/resterror"
+)
+
+func TestPublic(t *testing.T) {
+	orig := &resterror.Error{
+		Op:  "UserService.CreateUser",
+		Err: &resterror.Error{Op: "attachRole", Kind: resterror.EINTERNAL, Status: 500, Message: "syntax error at or near \"INSERT\""},
+	}
+
+	pub := orig.Public()
+	if pub == orig {
+		t.Fatal("expected a new *Error")
+	}
+	if pub.Op != "" || pub.Err != nil {
+		t.Fatalf("expected no internal fields, got Op=%q Err=%v", pub.Op, pub.Err)
+	}
+	if pub.Kind != resterror.EINTERNAL || pub.Status != 500 || pub.Message != resterror.MsgInternal {
+		t.Fatalf("unexpected public copy: %#v", pub)
+	}
+
+	pub.Kind, pub.Message = resterror.OTHER, "changed"
+	if orig.Kind != "" || orig.Message != "" || orig.Op != "UserService.CreateUser" {
+		t.Fatalf("original was mutated: %#v", orig)
+	}
+
+	violation := resterror.FieldViolation{Field: "email", Description: "Email is invalid."}
+	invalid := resterror.Invalid("CreateUser", "Email is invalid.").WithErrorDetails(violation)
+	pub = invalid.Public()
+	pub.ErrorDetails[0] = resterror.FieldViolation{Field: "name"}
+	if invalid.ErrorDetails[0] != violation {
+		t.Fatalf("original ErrorDetails were mutated: %#v", invalid.ErrorDetails)
+	}
+}
+
+func TestPublic_ClientError(t *testing.T) {
+	e := &resterror.Error{Op: "CreateUser", Kind: resterror.EINVALID, Status: 422, Message: "Username is required."}
+	if got := e.Public().Message; got != "Username is required." {
+		t.Fatalf("Message=%q", got)
+	}
+}
+
+func TestResponseBody_NoInternalFields(t *testing.T) {
+	e := &resterror.Error{Op: "insertUser", Kind: resterror.ECONFLICT, Status: 409, Message: "Username is already in use.", Err: errors.New("pq: unique_violation")}
+	body, err := e.ResponseBody()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(body), "insertUser") || strings.Contains(string(body), "unique_violation") {
+		t.Fatalf("body leaks internal fields: %s", body)
+	}
+
+	var got map[string]interface{}
+	if err := json.Unmarshal(body, &got); err != nil {
+		t.Fatal(err)
+	}
+	if got["kind"] != resterror.ECONFLICT || got["message"] != "Username is already in use." {
+		t.Fatalf("unexpected body: %s", body)
+	}
+}
+
+func TestResponseBody_Hint(t *testing.T) {
+	e := &resterror.Error{Kind: resterror.EINVALID, Status: 422, Message: "Unknown field."}
+	body, _ := e.ResponseBody()
+	if strings.Contains(string(body), "hint") {
+		t.Fatalf("empty hint is serialized: %s", body)
+	}
+
+	wrapped := &resterror.Error{Op: "GetUser", Err: e.WithHint("Pass ?include=profile to expand this field.")}
+	body, _ = wrapped.ResponseBody()
+	want := `{"kind":"invalid","status":422,"message":"Unknown field.","hint":"Pass ?include=profile to expand this field."}`
+	if string(body) != want {
+		t.Fatalf("body=%s", body)
+	}
+}
+
+func TestResponseBody_Remediation(t *testing.T) {
+	e := (&resterror.Error{Kind: resterror.ENOTFOUND, Message: "User not found."}).
+		WithRemediation("Try searching by email instead.")
+	wrapped := &resterror.Error{Op: "FindUser", Err: e.WithHint("Pass ?email= to search by email.")}
+	body, _ := wrapped.ResponseBody()
+	want := `{"kind":"item_does_not_exist","status":404,"message":"User not found.",` +
+		`"hint":"Pass ?email= to search by email.","remediation":"Try searching by email instead."}`
+	if string(body) != want {
+		t.Fatalf("body=%s", body)
+	}
+}
+
+func TestResponseBody_Detail(t *testing.T) {
+	e := &resterror.Error{Kind: resterror.ECONFLICT, Status: 409, Message: "Payment failed."}
+	body, _ := e.ResponseBody()
+	if strings.Contains(string(body), "detail") {
+		t.Fatalf("empty detail is serialized: %s", body)
+	}
+
+	e = e.WithDetailText("Your card was declined, try another payment method.")
+	wrapped := &resterror.Error{Op: "Checkout", Err: e}
+	body, _ = wrapped.ResponseBody()
+	want := `{"kind":"conflict","status":409,"message":"Payment failed.","detail":"Your card was declined, try another payment method."}`
+	if string(body) != want {
+		t.Fatalf("body=%s", body)
+	}
+	if got := resterror.ErrorMessage(wrapped); got != "Payment failed." {
+		t.Fatalf("ErrorMessage()=%q", got)
+	}
+
+	// Detail and Details coexist.
+	e.Details = map[string]interface{}{"decline_code": "insufficient_funds"}
+	body, _ = wrapped.ResponseBody()
+	want = `{"kind":"conflict","status":409,"message":"Payment failed.","detail":"Your card was declined, try another payment method.","details":{"decline_code":"insufficient_funds"}}`
+	if string(body) != want {
+		t.Fatalf("body=%s", body)
+	}
+}
+
+func TestResponseHeaders_Deprecation(t *testing.T) {
+	e := &resterror.Error{Kind: resterror.ENOTFOUND, Status: 404}
+	_, headers := e.ResponseHeaders()
+	if _, ok := headers["Deprecation"]; ok {
+		t.Fatalf("unexpected Deprecation header: %v", headers)
+	}
+	if _, ok := headers["Sunset"]; ok {
+		t.Fatalf("unexpected Sunset header: %v", headers)
+	}
+
+	sunset := time.Date(2027, time.March, 1, 9, 30, 0, 0, time.FixedZone("AST", -4*60*60))
+	wrapped := &resterror.Error{Op: "GET /v1/users", Err: &resterror.Error{Kind: resterror.ENOTFOUND, Status: 404, Deprecation: sunset}}
+	status, headers := wrapped.ResponseHeaders()
+	if status != 404 {
+		t.Fatalf("status=%d", status)
+	}
+	if headers["Deprecation"] != "true" || headers["Sunset"] != "Mon, 01 Mar 2027 13:30:00 GMT" {
+		t.Fatalf("headers=%v", headers)
+	}
+}
+
+func TestMarkInternal(t *testing.T) {
+	e := &resterror.Error{
+		Kind:    resterror.EINVALID,
+		Status:  422,
+		Message: "Payment declined.",
+		Details: map[string]interface{}{"decline_code": "do_not_honor", "gateway_ref": "gw_8f3a"},
+	}
+	e = e.MarkInternal("gateway_ref")
+	wrapped := &resterror.Error{Op: "Checkout", Err: e}
+
+	body, _ := wrapped.ResponseBody()
+	if want := `{"kind":"invalid","status":422,"message":"Payment declined.","details":{"decline_code":"do_not_honor"}}`; string(body) != want {
+		t.Fatalf("body=%s", body)
+	}
+
+	var logs bytes.Buffer
+	slog.New(slog.NewJSONHandler(&logs, nil)).LogAttrs(context.Background(), slog.LevelInfo, "request failed", resterror.LogAttrs(wrapped)...)
+	if !strings.Contains(logs.String(), `"gateway_ref":"gw_8f3a"`) {
+		t.Fatalf("logs=%s", &logs)
+	}
+
+	// All the details of server errors are internal.
+	e = &resterror.Error{Kind: resterror.EINTERNAL, Details: map[string]interface{}{"query": "SELECT 1"}}
+	if pub := e.Public(); pub.Details != nil {
+		t.Fatalf("public details of a 500=%v", pub.Details)
+	}
+}
+
+func TestClientSafeMessage_AllowMessage(t *testing.T) {
+	vetted := &resterror.Error{Kind: resterror.EINTERNAL, Message: "Payment provider temporarily unavailable.", AllowMessage: true}
+	if got := resterror.ClientSafeMessage(&resterror.Error{Op: "Checkout", Err: vetted}); got != vetted.Message {
+		t.Fatalf("ClientSafeMessage(vetted)=%q", got)
+	}
+
+	unvetted := &resterror.Error{Kind: resterror.EINTERNAL, Message: "stripe: 503 from api.stripe.com"}
+	if got := resterror.ClientSafeMessage(unvetted); got != resterror.MsgInternal {
+		t.Fatalf("ClientSafeMessage(unvetted)=%q", got)
+	}
+	body, _ := unvetted.ResponseBody()
+	if strings.Contains(string(body), "stripe") {
+		t.Fatalf("body=%s", body)
+	}
+}
+
+func TestAssertClientError(t *testing.T) {
+	for _, err := range []error{
+		nil,
+		&resterror.Error{Kind: resterror.ENOTFOUND},
+		fmt.Errorf("find user: %w", &resterror.Error{Kind: resterror.ENOTFOUND}),
+		&resterror.ValidationError{Fields: map[string]string{"name": "is required"}},
+	} {
+		if got := resterror.AssertClientError(err); got != nil {
+			t.Fatalf("AssertClientError(%v)=%v", err, got)
+		}
+	}
+
+	err := resterror.AssertClientError(errors.New("boom"))
+	if err == nil || !strings.Contains(err.Error(), "*errors.errorString") {
+		t.Fatalf("AssertClientError(bare)=%v", err)
+	}
+}
+
+func TestPublic_DocsURL(t *testing.T) {
+	defer resterror.SetDefaultRegistry(resterror.DefaultRegistry())
+
+	notFound := &resterror.Error{Op: "UserService.FindUserByID", Err: &resterror.Error{Kind: resterror.ENOTFOUND}}
+	if got := notFound.Public().DocsURL; got != "" {
+		t.Fatalf("DocsURL without base=%q", got)
+	}
+
+	resterror.SetDocsBaseURL("https://docs.example.com/errors/")
+	if got, want := notFound.Public().DocsURL, "https://docs.example.com/errors/item_does_not_exist"; got != want {
+		t.Fatalf("DocsURL=%q, want %q", got, want)
+	}
+
+	// An explicit DocsURL takes precedence over the page of the kind.
+	custom := &resterror.Error{Kind: resterror.EQUOTA, DocsURL: "https://docs.example.com/billing/plans"}
+	body, err := custom.ResponseBody()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `"docs_url":"https://docs.example.com/billing/plans"`; !strings.Contains(string(body), want) {
+		t.Fatalf("body=%s, want %s", body, want)
+	}
+}
+
+func TestClientSafeMessage_UserMessage(t *testing.T) {
+	tests := []struct {
+		name string
+		err  *resterror.Error
+	}{
+		{"client error", &resterror.Error{Kind: resterror.EEXIST, Message: "unique_violation on users_username_key", UserMessage: "Username is already in use."}},
+		{"server error", &resterror.Error{Kind: resterror.EINTERNAL, Message: "unique_violation on users_username_key", UserMessage: "Username is already in use."}},
+	}
+	for _, tt := range tests {
+		err := &resterror.Error{Op: "UserService.CreateUser", Err: tt.err}
+		if got := resterror.ClientSafeMessage(err); got != "Username is already in use." {
+			t.Errorf("%s: ClientSafeMessage()=%q", tt.name, got)
+		}
+		body, _ := err.ResponseBody()
+		if strings.Contains(string(body), "unique_violation") {
+			t.Errorf("%s: internal message is sent: %s", tt.name, body)
+		}
+		if got := resterror.ErrorMessage(err); got != "unique_violation on users_username_key" {
+			t.Errorf("%s: ErrorMessage()=%q", tt.name, got)
+		}
+	}
+}
+
+func TestErrorStatus(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{"nil", nil, 0},
+		{"explicit", &resterror.Error{Op: "CreateUser", Err: &resterror.Error{Kind: resterror.EINVALID, Status: 400}}, 400},
+		{"outermost status", &resterror.Error{Status: 503, Err: &resterror.Error{Status: 400}}, 503},
+		{"kind", fmt.Errorf("lookup: %w", &resterror.Error{Kind: resterror.ENOTFOUND}), 404},
+		{"plain", errors.New("boom"), 500},
+	}
+	for _, tt := range tests {
+		if got := resterror.ErrorStatus(tt.err); got != tt.want {
+			t.Errorf("%s: ErrorStatus()=%d, want %d", tt.name, got, tt.want)
+		}
+	}
+}
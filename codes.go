@@ -1,5 +1,7 @@
 package error
 
+import "net/http"
+
 // Types of errors.
 //
 // The values of the error types are common between both
@@ -16,4 +18,93 @@ const (
 	OTHER            = "other"               // Unclassified error
 	MethodNotAllowed = "method_not_allowed"  // HTTP method not allowed
 	EPARSE           = "parse_error"
+	ENOTFOUND_ROUTE  = "route_not_found" // No route matches the request
+	EQUOTA           = "quota_exceeded"  // Plan limit reached
 )
+
+// builtinKinds maps the kinds above to their default HTTP status code and
+// message. Kinds without a message use the default message, see
+// SetDefaultMessage.
+var builtinKinds = map[string]kindInfo{
+	ECONFLICT:        {Status: http.StatusConflict},
+	PERMISSION:       {Status: http.StatusForbidden},
+	EINTERNAL:        {Status: http.StatusInternalServerError},
+	EINVALID:         {Status: http.StatusUnprocessableEntity, Message: MsgValidation},
+	ENOTFOUND:        {Status: http.StatusNotFound, Message: MsgNotFound},
+	EEXIST:           {Status: http.StatusConflict},
+	OTHER:            {Status: http.StatusInternalServerError},
+	MethodNotAllowed: {Status: http.StatusMethodNotAllowed, Message: MsgMethodNotAllowed},
+	EPARSE:           {Status: http.StatusBadRequest, Message: MsgDecodeBody},
+	ENOTFOUND_ROUTE:  {Status: http.StatusNotFound, Message: MsgNotFound},
+	EQUOTA:           {Status: http.StatusTooManyRequests, Message: MsgQuota},
+}
+
+// kindInfo is the default HTTP status code and message of a kind.
+type kindInfo struct {
+	Status  int
+	Message string
+}
+
+// StatusForKind returns the default HTTP status code of kind.
+// Unknown kinds return the default status, see SetDefaultStatus.
+func StatusForKind(kind string) int {
+	return DefaultRegistry().StatusForKind(kind)
+}
+
+// KindForStatus returns the kind of the HTTP status code.
+//
+// When several kinds share a status the most generic one is returned, so 409
+// returns ECONFLICT rather than EEXIST, 404 returns ENOTFOUND rather than
+// ENOTFOUND_ROUTE, and 500 returns EINTERNAL rather than OTHER. Other server
+// errors return EINTERNAL and any other unknown status returns OTHER.
+func KindForStatus(status int) string {
+	switch status {
+	case http.StatusConflict:
+		return ECONFLICT
+	case http.StatusNotFound:
+		return ENOTFOUND
+	case http.StatusInternalServerError:
+		return EINTERNAL
+	}
+	for kind, info := range builtinKinds {
+		if info.Status == status {
+			return kind
+		}
+	}
+	if status >= 500 {
+		return EINTERNAL
+	}
+	return OTHER
+}
+
+// RegisterKind registers a custom kind with its default HTTP status code and
+// message in the default registry. An empty message means the default
+// message, see SetDefaultMessage.
+func RegisterKind(kind string, status int, message string) {
+	updateDefaultRegistry(func(r *Registry) *Registry { return r.WithKind(kind, status, message) })
+}
+
+// RegisterKindParent makes kind a descendant of parent in the default
+// registry, so that a precise kind of the database layer is still matched by
+// the broad category handlers check:
+//
+//	resterror.RegisterKindParent(EUNIQUE_VIOLATION, resterror.ECONFLICT)
+//
+//	resterror.Is(resterror.ECONFLICT, err) // true for EUNIQUE_VIOLATION too
+//
+// Is and the predicates such as IsConflict match descendants. A kind without
+// a status or message of its own uses those of its closest ancestor which
+// has one.
+func RegisterKindParent(kind, parent string) {
+	updateDefaultRegistry(func(r *Registry) *Registry { return r.WithKindParent(kind, parent) })
+}
+
+// RegisterCode maps kind to a stable numeric code in the default registry,
+// sent in the code of the response bodies of its errors, for clients which
+// can't rely on the kind string. Kinds have no code by default.
+//
+// Like kinds, codes are part of the API: once published, a code must never
+// be reassigned.
+func RegisterCode(kind string, code int) {
+	updateDefaultRegistry(func(r *Registry) *Registry { return r.WithCode(kind, code) })
+}
@@ -0,0 +1,107 @@
+package error_test
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	resterror "github.com/truescotian/resterror"
+)
+
+func TestStatusForKind(t *testing.T) {
+	if got := resterror.StatusForKind(resterror.ENOTFOUND); got != http.StatusNotFound {
+		t.Fatalf("StatusForKind(ENOTFOUND)=%d", got)
+	}
+	if got := resterror.StatusForKind("teapot"); got != http.StatusInternalServerError {
+		t.Fatalf("StatusForKind(unknown)=%d", got)
+	}
+}
+
+func TestSetDefaults(t *testing.T) {
+	resterror.SetDefaultStatus(http.StatusBadRequest)
+	resterror.SetDefaultMessage("Something went wrong, our team is on it.")
+	defer resterror.SetDefaultStatus(http.StatusInternalServerError)
+	defer resterror.SetDefaultMessage(resterror.MsgInternal)
+
+	if got := resterror.StatusForKind("teapot"); got != http.StatusBadRequest {
+		t.Fatalf("StatusForKind(unknown)=%d", got)
+	}
+	if got := resterror.StatusForKind(resterror.EINVALID); got != http.StatusUnprocessableEntity {
+		t.Fatalf("StatusForKind(EINVALID)=%d", got)
+	}
+	if got := resterror.ErrorMessage(&resterror.Error{Kind: "teapot"}); got != "Something went wrong, our team is on it." {
+		t.Fatalf("ErrorMessage(unknown)=%q", got)
+	}
+	if got := resterror.ErrorMessage(&resterror.Error{Kind: resterror.ENOTFOUND, Message: "User not found."}); got != "User not found." {
+		t.Fatalf("ErrorMessage(ENOTFOUND)=%q", got)
+	}
+}
+
+func TestKindForStatus(t *testing.T) {
+	tests := map[int]string{
+		http.StatusConflict:            resterror.ECONFLICT,
+		http.StatusNotFound:            resterror.ENOTFOUND,
+		http.StatusInternalServerError: resterror.EINTERNAL,
+		http.StatusBadGateway:          resterror.EINTERNAL,
+		http.StatusTeapot:              resterror.OTHER,
+	}
+	for status, want := range tests {
+		if got := resterror.KindForStatus(status); got != want {
+			t.Errorf("KindForStatus(%d)=%q, want %q", status, got, want)
+		}
+	}
+}
+
+func TestRegisterCode(t *testing.T) {
+	defer resterror.SetDefaultRegistry(resterror.DefaultRegistry())
+	resterror.RegisterCode(resterror.ENOTFOUND, 1004)
+
+	err := &resterror.Error{Op: "UserService.FindUserByID", Err: &resterror.Error{Kind: resterror.ENOTFOUND}}
+	body, _ := err.ResponseBody()
+	if want := `{"kind":"item_does_not_exist","code":1004,"status":404,"message":"The requested resource was not found."}`; string(body) != want {
+		t.Fatalf("body=%s, want %s", body, want)
+	}
+
+	// An explicit Code takes precedence over that of the kind, and kinds
+	// without a code don't send one.
+	if got := (&resterror.Error{Kind: resterror.ENOTFOUND, Code: 1005}).Public().Code; got != 1005 {
+		t.Fatalf("Code=%d, want 1005", got)
+	}
+	body, _ = (&resterror.Error{Kind: resterror.EINVALID}).ResponseBody()
+	if strings.Contains(string(body), "code") {
+		t.Fatalf("body=%s", body)
+	}
+}
+
+func TestRegisterKindParent(t *testing.T) {
+	defer resterror.SetDefaultRegistry(resterror.DefaultRegistry())
+	const (
+		eUniqueViolation = "unique_violation"
+		eUsernameTaken   = "username_taken"
+	)
+	resterror.RegisterKindParent(eUniqueViolation, resterror.ECONFLICT)
+	resterror.RegisterKindParent(eUsernameTaken, eUniqueViolation)
+	resterror.RegisterKind(eUsernameTaken, 0, "Username is already in use.")
+
+	err := &resterror.Error{Op: "CreateUser", Err: &resterror.Error{Op: "insertUser", Kind: eUsernameTaken}}
+	for _, kind := range []string{eUsernameTaken, eUniqueViolation, resterror.ECONFLICT} {
+		if !resterror.Is(kind, err) {
+			t.Errorf("Is(%s)=false", kind)
+		}
+	}
+	if resterror.Is(resterror.EEXIST, err) || resterror.Is(eUsernameTaken, &resterror.Error{Kind: eUniqueViolation}) {
+		t.Fatal("Is() matched a sibling or a descendant")
+	}
+	if !resterror.IsConflict(err) {
+		t.Fatal("IsConflict()=false")
+	}
+
+	// The status is inherited from the closest ancestor with one, the
+	// message is the kind's own.
+	if got := resterror.StatusForKind(eUsernameTaken); got != 409 {
+		t.Fatalf("StatusForKind()=%d, want 409", got)
+	}
+	if got := resterror.ErrorMessage(err); got != "Username is already in use." {
+		t.Fatalf("ErrorMessage()=%q", got)
+	}
+}
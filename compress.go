@@ -0,0 +1,91 @@
+package error
+
+import (
+	"compress/gzip"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// acceptsGzip reports whether the client of r accepts gzip encoded bodies.
+func acceptsGzip(r *http.Request) bool {
+	for _, enc := range strings.Split(r.Header.Get("Accept-Encoding"), ",") {
+		name, params, _ := strings.Cut(enc, ";")
+		if strings.TrimSpace(name) != "gzip" {
+			continue
+		}
+		_, q, ok := strings.Cut(params, "q=")
+		if !ok {
+			return true
+		}
+		weight, err := strconv.ParseFloat(strings.TrimSpace(q), 64)
+		return err == nil && weight > 0
+	}
+	return false
+}
+
+// gzipETag returns the ETag of the gzip encoding of the representation whose
+// ETag is etag. A strong ETag identifies the exact bytes, so the compressed
+// body gets one of its own.
+func gzipETag(etag string) string {
+	return strings.TrimSuffix(etag, `"`) + `-gzip"`
+}
+
+// gzipWriter is a http.ResponseWriter compressing the error body written to
+// it if it is larger than threshold. It expects the body to be written at
+// once, as WriteError does, and to be closed once the response is written.
+type gzipWriter struct {
+	http.ResponseWriter
+	threshold int
+	status    int
+	written   bool
+}
+
+// WriteHeader holds the status until the body is known, unless the status
+// doesn't allow a body.
+func (w *gzipWriter) WriteHeader(status int) {
+	if w.written {
+		return
+	}
+	w.status = status
+	if !bodyAllowed(status) {
+		w.written = true
+		w.ResponseWriter.WriteHeader(status)
+	}
+}
+
+// close writes the status held by WriteHeader if no body followed it.
+func (w *gzipWriter) close() {
+	if !w.written && w.status != 0 {
+		w.written = true
+		w.ResponseWriter.WriteHeader(w.status)
+	}
+}
+
+func (w *gzipWriter) Write(body []byte) (int, error) {
+	if w.written {
+		return w.ResponseWriter.Write(body)
+	}
+	w.written = true
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	if len(body) <= w.threshold {
+		w.ResponseWriter.WriteHeader(w.status)
+		return w.ResponseWriter.Write(body)
+	}
+
+	h := w.Header()
+	h.Set("Content-Encoding", "gzip")
+	h.Del("Content-Length")
+	if etag := h.Get("ETag"); etag != "" {
+		h.Set("ETag", gzipETag(etag))
+	}
+	w.ResponseWriter.WriteHeader(w.status)
+
+	gz := gzip.NewWriter(w.ResponseWriter)
+	if _, err := gz.Write(body); err != nil {
+		return 0, err
+	}
+	return len(body), gz.Close()
+}
@@ -0,0 +1,133 @@
+package error_test
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	resterror "github.com/truescotian/resterror"
+)
+
+func TestHandler_CompressThreshold(t *testing.T) {
+	e := &resterror.Error{Kind: resterror.EINVALID, Status: 422, Message: strings.Repeat("Too many problems. ", 10)}
+	want, _ := e.ResponseBody()
+
+	tests := []struct {
+		threshold      int
+		acceptEncoding string
+		compressed     bool
+	}{
+		{64, "gzip, deflate", true},
+		{64, "br;q=1.0, gzip;q=0.5", true},
+		{64, "gzip;q=0", false},
+		{64, "", false},
+		{4096, "gzip", false},
+		{0, "gzip", false},
+	}
+	for _, tt := range tests {
+		h := &resterror.Handler{
+			Fn:                func(http.ResponseWriter, *http.Request) error { return e },
+			Logger:            discard,
+			CompressThreshold: tt.threshold,
+		}
+		r := httptest.NewRequest(http.MethodPost, "/users/batch", nil)
+		r.Header.Set("Accept-Encoding", tt.acceptEncoding)
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, r)
+
+		if rec.Code != 422 {
+			t.Errorf("%+v: status=%d", tt, rec.Code)
+		}
+		body := rec.Body.Bytes()
+		if got := rec.Header().Get("Content-Encoding") == "gzip"; got != tt.compressed {
+			t.Errorf("%+v: compressed=%v", tt, got)
+			continue
+		}
+		if tt.compressed {
+			gz, err := gzip.NewReader(rec.Body)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if body, err = io.ReadAll(gz); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if string(body) != string(want) {
+			t.Errorf("%+v: body=%s", tt, body)
+		}
+	}
+}
+
+func TestHandler_CompressThresholdBodiless(t *testing.T) {
+	// Statuses without a body must reach the client, not the implicit 200 a
+	// server sends when nothing is written after WriteHeader.
+	for _, status := range []int{http.StatusNoContent, http.StatusNotModified} {
+		srv := httptest.NewServer(&resterror.Handler{
+			Fn: func(http.ResponseWriter, *http.Request) error {
+				return &resterror.Error{Status: status, Message: strings.Repeat("Nothing to do. ", 10)}
+			},
+			Logger:            discard,
+			CompressThreshold: 1,
+		})
+		req, _ := http.NewRequest(http.MethodGet, srv.URL, nil)
+		req.Header.Set("Accept-Encoding", "gzip")
+		resp, err := http.DefaultClient.Do(req)
+		srv.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != status {
+			t.Errorf("status=%d, want %d", resp.StatusCode, status)
+		}
+	}
+}
+
+func TestHandler_CompressThresholdETag(t *testing.T) {
+	h := &resterror.Handler{
+		Fn: func(http.ResponseWriter, *http.Request) error {
+			return &resterror.Error{Kind: resterror.ENOTFOUND, Status: 404, Message: strings.Repeat("Not found. ", 10)}
+		},
+		Logger:            discard,
+		Registry:          resterror.NewRegistry().WithETags(true),
+		CompressThreshold: 64,
+	}
+	serve := func(acceptEncoding, ifNoneMatch string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodGet, "/users/42", nil)
+		r.Header.Set("Accept-Encoding", acceptEncoding)
+		if ifNoneMatch != "" {
+			r.Header.Set("If-None-Match", ifNoneMatch)
+		}
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, r)
+		return rec
+	}
+
+	identity, gzipped := serve("", ""), serve("gzip", "")
+	if identity.Header().Get("Content-Encoding") != "" || gzipped.Header().Get("Content-Encoding") != "gzip" {
+		t.Fatal("unexpected encodings")
+	}
+	for _, rec := range []*httptest.ResponseRecorder{identity, gzipped} {
+		if got := rec.Header().Get("Vary"); got != "Accept-Encoding" {
+			t.Errorf("Vary=%q", got)
+		}
+	}
+	etag, gzipETag := identity.Header().Get("ETag"), gzipped.Header().Get("ETag")
+	if etag == "" || gzipETag == "" || etag == gzipETag {
+		t.Fatalf("ETags %q and %q, want distinct ones", etag, gzipETag)
+	}
+
+	// Each encoding revalidates against its own ETag.
+	if rec := serve("gzip", gzipETag); rec.Code != http.StatusNotModified || rec.Header().Get("ETag") != gzipETag {
+		t.Errorf("gzip revalidation: status=%d ETag=%q", rec.Code, rec.Header().Get("ETag"))
+	}
+	if rec := serve("", etag); rec.Code != http.StatusNotModified || rec.Header().Get("ETag") != etag {
+		t.Errorf("identity revalidation: status=%d ETag=%q", rec.Code, rec.Header().Get("ETag"))
+	}
+	if rec := serve("", gzipETag); rec.Code != http.StatusNotFound {
+		t.Errorf("identity with the gzip ETag: status=%d", rec.Code)
+	}
+}
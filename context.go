@@ -0,0 +1,93 @@
+package error
+
+import (
+	"context"
+	"sync"
+)
+
+// contextKey is the type of the keys of the values this package stores in
+// a context.Context.
+type contextKey int
+
+const (
+	opContextKey contextKey = iota
+	collectorContextKey
+	requestIDContextKey
+)
+
+// ContextWithOp returns a copy of ctx carrying op as the base Op of the errors
+// created with NewErrorCtx.
+func ContextWithOp(ctx context.Context, op string) context.Context {
+	return context.WithValue(ctx, opContextKey, op)
+}
+
+// OpFromContext returns the base Op carried by ctx, if any.
+func OpFromContext(ctx context.Context) string {
+	op, _ := ctx.Value(opContextKey).(string)
+	return op
+}
+
+// NewErrorCtx returns an Error using the passed arguments, like NewError,
+// with the Op and the RequestID taken from ctx, and stamped by the
+// registered enrichers. See ContextWithOp, ContextWithRequestID and
+// RegisterEnricher.
+func NewErrorCtx(ctx context.Context, status int, message string, kind string, err error) *Error {
+	e := NewError(OpFromContext(ctx), status, message, kind, err)
+	e.RequestID = RequestIDFromContext(ctx)
+	DefaultRegistry().enrich(ctx, e)
+	return e
+}
+
+// PrependOp wraps err with the base Op carried by ctx so that the logical
+// stack trace of err begins with it, followed by the ops of err itself.
+//
+// err is returned unchanged if it is nil, if ctx carries no base Op, or if
+// err already begins with it (e.g. it was created with NewErrorCtx).
+func PrependOp(ctx context.Context, err error) error {
+	op := OpFromContext(ctx)
+	if err == nil || op == "" {
+		return err
+	} else if e, ok := err.(*Error); ok && e.Op == op {
+		return err
+	}
+	return &Error{Op: op, Err: err}
+}
+
+// collector accumulates the errors added to a request context.
+type collector struct {
+	mu   sync.Mutex
+	errs []*Error
+}
+
+// withCollector returns a copy of ctx which accumulates the errors passed to
+// AddError.
+func withCollector(ctx context.Context) context.Context {
+	return context.WithValue(ctx, collectorContextKey, &collector{})
+}
+
+// AddError records a non-fatal error on the request context. If the handler
+// ultimately fails, the recorded errors are included in the response as its
+// causes. AddError is safe for concurrent use.
+//
+// AddError does nothing if ctx doesn't come from a Handler.
+func AddError(ctx context.Context, e *Error) {
+	c, ok := ctx.Value(collectorContextKey).(*collector)
+	if !ok || e == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.errs = append(c.errs, e)
+}
+
+// CollectedErrors returns the errors recorded on ctx with AddError, in the
+// order they were added.
+func CollectedErrors(ctx context.Context) []*Error {
+	c, ok := ctx.Value(collectorContextKey).(*collector)
+	if !ok {
+		return nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]*Error(nil), c.errs...)
+}
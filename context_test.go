@@ -0,0 +1,45 @@
+package error_test
+
+import (
+	"context"
+	"testing"
+
+	resterror "github.com/truescotian/resterror"
+)
+
+func TestPrependOp(t *testing.T) {
+	ctx := resterror.ContextWithOp(context.Background(), "GET /users/{id}")
+	err := &resterror.Error{
+		Op:  "UserService.FindUserByID",
+		Err: &resterror.Error{Op: "findUser", Kind: resterror.ENOTFOUND},
+	}
+
+	got := resterror.PrependOp(ctx, err)
+	if trace := resterror.OpTrace(got); trace != "GET /users/{id}: UserService.FindUserByID: findUser" {
+		t.Fatalf("OpTrace=%q", trace)
+	}
+	if kind := resterror.ErrorKind(got); kind != resterror.ENOTFOUND {
+		t.Fatalf("ErrorKind=%q", kind)
+	}
+
+	// Errors which already start with the base op are left alone.
+	own := resterror.NewErrorCtx(ctx, 404, "", resterror.ENOTFOUND, nil)
+	if got := resterror.PrependOp(ctx, own); got != own {
+		t.Fatalf("PrependOp re-wrapped %v", got)
+	}
+
+	if got := resterror.PrependOp(context.Background(), err); got != err {
+		t.Fatalf("PrependOp without base op wrapped %v", got)
+	}
+	if got := resterror.PrependOp(ctx, nil); got != nil {
+		t.Fatalf("PrependOp(nil)=%v", got)
+	}
+}
+
+func TestAddError_NoCollector(t *testing.T) {
+	ctx := context.Background()
+	resterror.AddError(ctx, &resterror.Error{Kind: resterror.EINVALID})
+	if errs := resterror.CollectedErrors(ctx); errs != nil {
+		t.Fatalf("CollectedErrors()=%v", errs)
+	}
+}
@@ -0,0 +1,88 @@
+package error
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// dumpHeaders are the request headers included in a DebugDump. The values of
+// sensitiveHeaders are redacted.
+var dumpHeaders = []string{
+	"Accept",
+	"Accept-Language",
+	"Authorization",
+	"Content-Length",
+	"Content-Type",
+	"Cookie",
+	"User-Agent",
+	"X-Request-Id",
+}
+
+var sensitiveHeaders = map[string]bool{
+	"Authorization": true,
+	"Cookie":        true,
+}
+
+// Redacted replaces the values removed from a DebugDump.
+const Redacted = "[REDACTED]"
+
+// DebugDump returns a description of the request r which failed with err,
+// to be pasted in a support ticket: a curl command reproducing r followed by
+// the op trace, kind, status and cause of err.
+//
+// Only a few headers known to be safe are included, and the Authorization
+// and Cookie values are redacted. The redactors of the default registry are
+// applied to the whole dump, see RegisterRedactor. The body of r isn't read.
+func DebugDump(r *http.Request, err error) string {
+	return DefaultRegistry().DebugDump(r, err)
+}
+
+// DebugDump is like the package-level DebugDump but resolves err with r.
+func (r *Registry) DebugDump(req *http.Request, err error) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "curl -X %s %s", req.Method, shellQuote(requestURL(req)))
+	for _, name := range dumpHeaders {
+		values := req.Header.Values(name)
+		sort.Strings(values)
+		for _, v := range values {
+			if sensitiveHeaders[name] {
+				v = Redacted
+			}
+			fmt.Fprintf(&b, " -H %s", shellQuote(name+": "+v))
+		}
+	}
+	b.WriteByte('\n')
+
+	if err != nil {
+		fmt.Fprintf(&b, "op_trace: %s\n", strings.Join(r.ops(err), ": "))
+		fmt.Fprintf(&b, "kind: %s\n", r.errorKind(err))
+		fmt.Fprintf(&b, "status: %d\n", r.ErrorStatus(err))
+		fmt.Fprintf(&b, "cause: %s\n", Cause(err).Error())
+	}
+
+	return r.redact(b.String())
+}
+
+// redact returns s with the redactors of r applied, see WithRedactor.
+func (r *Registry) redact(s string) string {
+	for _, redact := range r.redactors {
+		s = redact(s)
+	}
+	return s
+}
+
+// requestURL returns the absolute URL of r.
+func requestURL(r *http.Request) string {
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+	return scheme + "://" + r.Host + r.URL.RequestURI()
+}
+
+// shellQuote quotes s for a POSIX shell.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
@@ -0,0 +1,44 @@
+package error_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	resterror "github.com/truescotian/resterror"
+)
+
+func TestDebugDump(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "/users?invite=jane@example.com", nil)
+	req.Header.Set("Authorization", "Bearer s3cr3t")
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Api-Key", "k3y")
+	err := &resterror.Error{Op: "CreateUser", Err: &resterror.Error{
+		Op:     "UserService.Insert",
+		Kind:   resterror.EEXIST,
+		Status: 409,
+		Err:    &resterror.Error{Message: "pq: duplicate key value violates unique constraint"},
+	}}
+
+	dump := resterror.NewRegistry().
+		WithRedactor(func(s string) string { return strings.ReplaceAll(s, "jane@example.com", resterror.Redacted) }).
+		DebugDump(req, err)
+
+	for _, want := range []string{
+		`curl -X POST 'http://example.com/users?invite=[REDACTED]' -H 'Authorization: [REDACTED]' -H 'Content-Type: application/json'`,
+		"op_trace: CreateUser: UserService.Insert\n",
+		"kind: item_already_exists\n",
+		"status: 409\n",
+		"cause: pq: duplicate key value violates unique constraint\n",
+	} {
+		if !strings.Contains(dump, want) {
+			t.Fatalf("dump doesn't contain %q:\n%s", want, dump)
+		}
+	}
+	for _, secret := range []string{"s3cr3t", "k3y", "jane@example.com"} {
+		if strings.Contains(dump, secret) {
+			t.Fatalf("dump leaks %q:\n%s", secret, dump)
+		}
+	}
+}
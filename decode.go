@@ -0,0 +1,87 @@
+package error
+
+import (
+	"encoding/json"
+	"errors"
+	"mime"
+	"net/http"
+	"sync/atomic"
+)
+
+// maxBodyBytes is the size limit of the bodies read by DecodeJSON.
+var maxBodyBytes atomic.Int64
+
+func init() {
+	maxBodyBytes.Store(1 << 20)
+}
+
+// SetMaxBodyBytes sets the size limit of the bodies read by DecodeJSON.
+// It defaults to 1MB.
+func SetMaxBodyBytes(n int64) {
+	maxBodyBytes.Store(n)
+}
+
+// DecodeJSON decodes the JSON body of r into v.
+//
+// It returns an EPARSE error if the Content-Type of r isn't application/json
+// (415), if the body is larger than the limit set by SetMaxBodyBytes (413), or
+// if the body isn't valid JSON for v (400). For the latter, the offset of the
+// error in the body is reported in Details.
+func DecodeJSON(r *http.Request, v interface{}) error {
+	const op = "DecodeJSON"
+
+	if mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type")); err != nil || mediaType != "application/json" {
+		return &Error{
+			Kind:    EPARSE,
+			Status:  http.StatusUnsupportedMediaType,
+			Message: MsgUnsupportedType,
+			Op:      op,
+		}
+	}
+
+	body := http.MaxBytesReader(nil, r.Body, maxBodyBytes.Load())
+	if err := json.NewDecoder(body).Decode(v); err != nil {
+		var (
+			maxBytesErr  *http.MaxBytesError
+			syntaxErr    *json.SyntaxError
+			unmarshalErr *json.UnmarshalTypeError
+		)
+		switch {
+		case errors.As(err, &maxBytesErr):
+			return &Error{
+				Kind:    EPARSE,
+				Status:  http.StatusRequestEntityTooLarge,
+				Message: MsgBodyTooLarge,
+				Op:      op,
+				Err:     err,
+			}
+		case errors.As(err, &syntaxErr):
+			return &Error{
+				Kind:    EPARSE,
+				Status:  http.StatusBadRequest,
+				Message: MsgDecodeBody,
+				Details: map[string]interface{}{"offset": syntaxErr.Offset},
+				Op:      op,
+				Err:     err,
+			}
+		case errors.As(err, &unmarshalErr):
+			return &Error{
+				Kind:    EPARSE,
+				Status:  http.StatusBadRequest,
+				Message: MsgDecodeBody,
+				Details: map[string]interface{}{"offset": unmarshalErr.Offset, "field": unmarshalErr.Field},
+				Op:      op,
+				Err:     err,
+			}
+		default:
+			return &Error{
+				Kind:    EPARSE,
+				Status:  http.StatusBadRequest,
+				Message: MsgDecodeBody,
+				Op:      op,
+				Err:     err,
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,67 @@
+package error_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	resterror "github.com/truescotian/resterror"
+)
+
+func TestDecodeJSON(t *testing.T) {
+	r := httptest.NewRequest(http.MethodPost, "/users", strings.NewReader(`{"username":"gopher"}`))
+	r.Header.Set("Content-Type", "application/json; charset=utf-8")
+
+	var v struct{ Username string }
+	if err := resterror.DecodeJSON(r, &v); err != nil {
+		t.Fatal(err)
+	}
+	if v.Username != "gopher" {
+		t.Fatalf("Username=%q", v.Username)
+	}
+}
+
+func TestDecodeJSON_WrongContentType(t *testing.T) {
+	r := httptest.NewRequest(http.MethodPost, "/users", strings.NewReader("username=gopher"))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	var v struct{}
+	e := resterror.Coerce(resterror.DecodeJSON(r, &v))
+	if e.Kind != resterror.EPARSE || e.Status != http.StatusUnsupportedMediaType || e.Message != "Expected application/json" {
+		t.Fatalf("DecodeJSON()=%#v", e)
+	}
+}
+
+func TestDecodeJSON_TooLarge(t *testing.T) {
+	resterror.SetMaxBodyBytes(16)
+	defer resterror.SetMaxBodyBytes(1 << 20)
+
+	r := httptest.NewRequest(http.MethodPost, "/users", strings.NewReader(`{"username":"a very long username"}`))
+	r.Header.Set("Content-Type", "application/json")
+
+	var v struct{ Username string }
+	e := resterror.Coerce(resterror.DecodeJSON(r, &v))
+	if e.Kind != resterror.EPARSE || e.Status != http.StatusRequestEntityTooLarge {
+		t.Fatalf("DecodeJSON()=%#v", e)
+	}
+}
+
+func TestDecodeJSON_Malformed(t *testing.T) {
+	r := httptest.NewRequest(http.MethodPost, "/users", strings.NewReader(`{"username": gopher}`))
+	r.Header.Set("Content-Type", "application/json")
+
+	var v struct{ Username string }
+	e := resterror.Coerce(resterror.DecodeJSON(r, &v))
+	if e.Kind != resterror.EPARSE || e.Status != http.StatusBadRequest {
+		t.Fatalf("DecodeJSON()=%#v", e)
+	}
+	if got := e.Details["offset"]; got != int64(14) {
+		t.Fatalf("offset=%v", got)
+	}
+
+	body, _ := e.ResponseBody()
+	if !strings.Contains(string(body), `"details":{"offset":14}`) {
+		t.Fatalf("body=%s", body)
+	}
+}
@@ -0,0 +1,116 @@
+package error
+
+import (
+	"encoding/json"
+	"strconv"
+	"time"
+)
+
+// ErrorDetail is a typed piece of structured data about an error, sent to
+// the client in the error_details of the response body. The details of this
+// package carry their type in an "@type" member, e.g.:
+//
+//	{"@type": "field_violation", "field": "email", "description": "Email is invalid."}
+//
+// A detail is encoded like any other value, so custom details must add the
+// "@type" member in their own MarshalJSON to follow that convention.
+//
+// Unlike Details, error details are meant for clients, so they are sent for
+// server errors too, e.g. a RetryInfo for a 503.
+type ErrorDetail interface {
+	// DetailType returns the "@type" of the detail.
+	DetailType() string
+}
+
+// FieldViolation is a field of a request which is invalid.
+type FieldViolation struct {
+	Field       string `json:"field"`
+	Description string `json:"description"`
+}
+
+// DetailType returns "field_violation".
+func (FieldViolation) DetailType() string { return "field_violation" }
+
+// MarshalJSON returns the JSON encoding of d with its "@type".
+func (d FieldViolation) MarshalJSON() ([]byte, error) {
+	type plain FieldViolation
+	return json.Marshal(struct {
+		Type string `json:"@type"`
+		plain
+	}{d.DetailType(), plain(d)})
+}
+
+// ResourceInfo describes the resource an error is about.
+// Ex: ResourceInfo{ResourceType: "user", ResourceName: "42"}.
+type ResourceInfo struct {
+	ResourceType string `json:"resource_type"`
+	ResourceName string `json:"resource_name"`
+	Description  string `json:"description,omitempty"`
+}
+
+// DetailType returns "resource_info".
+func (ResourceInfo) DetailType() string { return "resource_info" }
+
+// MarshalJSON returns the JSON encoding of d with its "@type".
+func (d ResourceInfo) MarshalJSON() ([]byte, error) {
+	type plain ResourceInfo
+	return json.Marshal(struct {
+		Type string `json:"@type"`
+		plain
+	}{d.DetailType(), plain(d)})
+}
+
+// RetryInfo tells the client how long to wait before retrying the request.
+// RetryDelay is encoded in seconds, e.g. "1.5s".
+type RetryInfo struct {
+	RetryDelay time.Duration
+}
+
+// DetailType returns "retry_info".
+func (RetryInfo) DetailType() string { return "retry_info" }
+
+// MarshalJSON returns the JSON encoding of d with its "@type".
+func (d RetryInfo) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Type       string `json:"@type"`
+		RetryDelay string `json:"retry_delay"`
+	}{d.DetailType(), strconv.FormatFloat(d.RetryDelay.Seconds(), 'f', -1, 64) + "s"})
+}
+
+// LocalizedMessage is the message of an error in the locale of the client,
+// sent next to the canonical Message, like google.rpc.LocalizedMessage.
+// Locale is a BCP 47 language tag.
+// Ex: LocalizedMessage{Locale: "fr-CA", Message: "Utilisateur introuvable."}.
+type LocalizedMessage struct {
+	Locale  string `json:"locale"`
+	Message string `json:"message"`
+}
+
+// DetailType returns "localized_message".
+func (LocalizedMessage) DetailType() string { return "localized_message" }
+
+// MarshalJSON returns the JSON encoding of d with its "@type".
+func (d LocalizedMessage) MarshalJSON() ([]byte, error) {
+	type plain LocalizedMessage
+	return json.Marshal(struct {
+		Type string `json:"@type"`
+		plain
+	}{d.DetailType(), plain(d)})
+}
+
+// WithErrorDetails returns a copy of e with details appended to its
+// ErrorDetails.
+func (e *Error) WithErrorDetails(details ...ErrorDetail) *Error {
+	c := e.Clone()
+	c.ErrorDetails = append(c.ErrorDetails, details...)
+	return c
+}
+
+// errorDetails returns a copy of the first ErrorDetails found in the chain
+// of Error.Err.
+func (r *Registry) errorDetails(err error) []ErrorDetail {
+	if e, ok := r.lookup(err, func(e *Error) bool { return len(e.ErrorDetails) != 0 }); ok {
+		return append([]ErrorDetail(nil), e.ErrorDetails...)
+	}
+	return nil
+}
@@ -0,0 +1,57 @@
+package error_test
+
+import (
+	"testing"
+	"time"
+
+	resterror "github.com/truescotian/resterror"
+)
+
+func TestErrorDetails(t *testing.T) {
+	inner := (&resterror.Error{Kind: resterror.EINVALID}).WithErrorDetails(
+		resterror.FieldViolation{Field: "email", Description: "Email is invalid."},
+		resterror.ResourceInfo{ResourceType: "user", ResourceName: "42"},
+	)
+	err := &resterror.Error{Op: "UserService.UpdateUser", Err: inner}
+
+	body, e := err.ResponseBody()
+	if e != nil {
+		t.Fatal(e)
+	}
+	want := `{"kind":"invalid","status":422,"message":"One or more fields are invalid.",` +
+		`"error_details":[{"@type":"field_violation","field":"email","description":"Email is invalid."},` +
+		`{"@type":"resource_info","resource_type":"user","resource_name":"42"}]}`
+	if string(body) != want {
+		t.Fatalf("body=%s, want %s", body, want)
+	}
+}
+
+func TestErrorDetails_ServerError(t *testing.T) {
+	err := (&resterror.Error{Status: 503, Kind: resterror.EINTERNAL}).
+		WithErrorDetails(resterror.RetryInfo{RetryDelay: 1500 * time.Millisecond})
+
+	body, e := err.ResponseBody()
+	if e != nil {
+		t.Fatal(e)
+	}
+	want := `{"kind":"internal","status":503,"message":"An internal error has occurred. Please contact technical support.",` +
+		`"error_details":[{"@type":"retry_info","retry_delay":"1.5s"}]}`
+	if string(body) != want {
+		t.Fatalf("body=%s, want %s", body, want)
+	}
+}
+
+func TestErrorDetails_LocalizedMessage(t *testing.T) {
+	err := resterror.NotFound("FindUser", "User not found.").
+		WithErrorDetails(resterror.LocalizedMessage{Locale: "fr-CA", Message: "Utilisateur introuvable."})
+
+	body, e := err.ResponseBody()
+	if e != nil {
+		t.Fatal(e)
+	}
+	want := `{"kind":"item_does_not_exist","status":404,"message":"User not found.",` +
+		`"error_details":[{"@type":"localized_message","locale":"fr-CA","message":"Utilisateur introuvable."}]}`
+	if string(body) != want {
+		t.Fatalf("body=%s, want %s", body, want)
+	}
+}
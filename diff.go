@@ -0,0 +1,68 @@
+package error
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// Equal reports whether a and b describe the same error, see Diff.
+func Equal(a, b error) bool {
+	return Diff(a, b) == ""
+}
+
+// Diff returns a human-readable description of the differences between a
+// and b, one line per field, or an empty string if there are none:
+//
+//	kind: "item_does_not_exist" != "invalid"
+//	ops: []string{"UserService.FindUserByID", "queryUser"} != []string{"UserService.FindUserByID"}
+//
+// The resolved Kind, Status, Code and Message are compared, along with the
+// op traces, the first Details, the Fields and the Tags of the chains.
+// Root causes are not compared, their text often embeds IDs.
+// Occurrence-specific data, such as the time, the stack or the request ID,
+// is ignored, so that two occurrences of an error are equal. Errors without
+// an *Error in their chain have nothing to resolve, so when neither has one
+// their texts are compared instead.
+func Diff(a, b error) string {
+	if a == nil || b == nil {
+		if a == b {
+			return ""
+		}
+		return fmt.Sprintf("error: %v != %v\n", a, b)
+	}
+
+	r := DefaultRegistry()
+	_, aok := r.asError(a)
+	_, bok := r.asError(b)
+	if !aok && !bok {
+		if a.Error() == b.Error() {
+			return ""
+		}
+		return fmt.Sprintf("error: %q != %q\n", a.Error(), b.Error())
+	}
+
+	var buf strings.Builder
+	diff := func(name string, x, y interface{}) {
+		if !reflect.DeepEqual(x, y) {
+			fmt.Fprintf(&buf, "%s: %#v != %#v\n", name, x, y)
+		}
+	}
+	diff("kind", r.errorKind(a), r.errorKind(b))
+	diff("status", r.ErrorStatus(a), r.ErrorStatus(b))
+	diff("code", r.errorCode(a), r.errorCode(b))
+	diff("message", r.ErrorMessage(a), r.ErrorMessage(b))
+	diff("ops", r.ops(a), r.ops(b))
+	diff("details", r.errorDetailsMap(a), r.errorDetailsMap(b))
+	diff("fields", r.errorFields(a), r.errorFields(b))
+	diff("tags", r.errorTags(a), r.errorTags(b))
+	return buf.String()
+}
+
+// errorDetailsMap returns the first Details found in the chain of err.
+func (r *Registry) errorDetailsMap(err error) map[string]interface{} {
+	if e, ok := r.lookup(err, func(e *Error) bool { return len(e.Details) != 0 }); ok {
+		return e.Details
+	}
+	return nil
+}
@@ -0,0 +1,48 @@
+package error_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	resterror "github.com/truescotian/resterror"
+)
+
+func TestDiff(t *testing.T) {
+	findUser := func(id int) error {
+		return &resterror.Error{
+			Op:   "UserService.FindUserByID",
+			Time: time.Now(),
+			Err:  (&resterror.Error{Op: "queryUser", Kind: resterror.ENOTFOUND, Message: "User not found."}).WithField("table", "users"),
+		}
+	}
+	if diff := resterror.Diff(findUser(1), findUser(2)); diff != "" {
+		t.Fatalf("occurrences differ:\n%s", diff)
+	}
+	if !resterror.Equal(nil, nil) || resterror.Equal(findUser(1), nil) {
+		t.Fatal("Equal() with nil errors")
+	}
+
+	other := &resterror.Error{Op: "UserService.FindUserByID", Kind: resterror.EINVALID, Message: "User not found."}
+	want := `kind: "item_does_not_exist" != "invalid"
+status: 404 != 422
+ops: []string{"UserService.FindUserByID", "queryUser"} != []string{"UserService.FindUserByID"}
+fields: map[string]interface {}{"table":"users"} != map[string]interface {}(nil)
+`
+	if got := resterror.Diff(findUser(1), other); got != want {
+		t.Fatalf("Diff()=\n%s\nwant\n%s", got, want)
+	}
+
+	// Causes vary between occurrences, e.g. with IDs, and aren't compared.
+	if !resterror.Equal(resterror.Internal("Sync", errors.New("timeout after 3s")), resterror.Internal("Sync", errors.New("timeout after 5s"))) {
+		t.Fatal("errors with different causes aren't equal")
+	}
+
+	// Plain errors have no resolved fields, their texts are compared.
+	if !resterror.Equal(errors.New("timeout"), errors.New("timeout")) {
+		t.Fatal("plain errors with the same text aren't equal")
+	}
+	if got, want := resterror.Diff(errors.New("timeout"), errors.New("EOF")), "error: \"timeout\" != \"EOF\"\n"; got != want {
+		t.Fatalf("Diff()=%q, want %q", got, want)
+	}
+}
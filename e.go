@@ -0,0 +1,55 @@
+package error
+
+import "fmt"
+
+// Msg is the Message of an error built by E.
+type Msg string
+
+// Kind is the Kind of an error built by E.
+type Kind string
+
+// E builds an *Error from its arguments, by type, for one-line wrapping at
+// every layer, like the errors package of upspin:
+//
+//	string  The Op.
+//	Msg     The Message.
+//	Kind    The Kind.
+//	int     The HTTP status code.
+//	error   The wrapped Err.
+//
+// If an argument is of another type, E returns an error describing it.
+// E panics if called with no arguments.
+//
+// Like the other constructors, E captures the call stack for the kind the
+// error resolves to, see SetCaptureStackFor.
+//
+//	return resterror.E("UserService.FindUser", resterror.Kind(resterror.ENOTFOUND), resterror.Msg("User not found."), err)
+func E(args ...interface{}) error {
+	return newE(1, args)
+}
+
+// newE is E, capturing the stack above skip frames of its caller.
+func newE(skip int, args []interface{}) error {
+	if len(args) == 0 {
+		panic("call to resterror.E with no arguments")
+	}
+	e := &Error{Time: DefaultRegistry().now()}
+	for _, arg := range args {
+		switch arg := arg.(type) {
+		case string:
+			e.Op = arg
+		case Msg:
+			e.Message = string(arg)
+		case Kind:
+			e.Kind = string(arg)
+		case int:
+			e.Status = arg
+		case error:
+			e.Err = arg
+		default:
+			return fmt.Errorf("unknown type %T, value %v in error call", arg, arg)
+		}
+	}
+	e.stack = DefaultRegistry().captureStack(ErrorKind(e), skip+1)
+	return e
+}
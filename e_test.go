@@ -0,0 +1,66 @@
+package error_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	resterror "github.com/truescotian/resterror"
+)
+
+func TestE(t *testing.T) {
+	defer resterror.SetDefaultRegistry(resterror.DefaultRegistry())
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	resterror.SetClock(func() time.Time { return now })
+
+	cause := errors.New("sql: no rows in result set")
+	err := resterror.E("UserService.FindUser", resterror.Kind(resterror.ENOTFOUND), resterror.Msg("User not found."), 404, cause)
+	want := &resterror.Error{Op: "UserService.FindUser", Kind: resterror.ENOTFOUND, Message: "User not found.", Status: 404, Err: cause, Time: now}
+	if !reflect.DeepEqual(err, want) {
+		t.Fatalf("E()=%#v", err)
+	}
+
+	// Wrapping at another layer.
+	err = resterror.E("GET /users/{id}", err)
+	if got := resterror.OpTrace(err); got != "GET /users/{id}: UserService.FindUser" {
+		t.Fatalf("OpTrace()=%q", got)
+	}
+	if resterror.ErrorKind(err) != resterror.ENOTFOUND {
+		t.Fatalf("ErrorKind()=%q", resterror.ErrorKind(err))
+	}
+
+	if err := resterror.E("FindUser", 4.2); err == nil || resterror.GetOp(err) != "" {
+		t.Fatalf("E() with a bad argument=%v", err)
+	}
+}
+
+func TestE_NoArguments(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Fatal("E() didn't panic")
+		}
+	}()
+	resterror.E()
+}
+
+func TestE_Stack(t *testing.T) {
+	for name, err := range map[string]error{
+		"E":           resterror.E("Sync", resterror.Kind(resterror.EINTERNAL), errors.New("timeout")),
+		"FromContext": resterror.FromContext(context.Background(), "Sync", resterror.Kind(resterror.EINTERNAL)),
+	} {
+		frames := err.(*resterror.Error).Frames()
+		if len(frames) == 0 || !strings.HasSuffix(frames[0].Function, ".TestE_Stack") {
+			t.Errorf("%s: Frames()=%v, want the caller first", name, frames)
+		}
+	}
+
+	// Client errors skip the cost by default, whether their kind is their
+	// own or the one of the wrapped error.
+	notFound := resterror.E("FindUser", resterror.Kind(resterror.ENOTFOUND))
+	if frames := resterror.E("GET /users/{id}", notFound).(*resterror.Error).Frames(); frames != nil {
+		t.Errorf("Frames()=%v, want none", frames)
+	}
+}
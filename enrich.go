@@ -0,0 +1,86 @@
+package error
+
+import (
+	"context"
+	"errors"
+)
+
+// Enricher stamps the data carried by ctx, such as the ID of the user of the
+// request, onto e, an error created in the scope of ctx. It is only called
+// on new errors, so it may modify e. See RegisterEnricher.
+type Enricher func(ctx context.Context, e *Error)
+
+// NewContextEnricher returns an Enricher stamping onto errors:
+//
+//   - the request ID carried by ctx, unless they have one, see
+//     ContextWithRequestID;
+//   - the user_id and tenant fields returned by userID and tenant, which
+//     extract them from the context of the application and may be nil;
+//   - the deadline field if ctx has a deadline, and the ctx_err field if ctx
+//     is done, e.g. "context deadline exceeded".
+//
+// Fields are logged but never sent to clients, see Error.Fields.
+func NewContextEnricher(userID, tenant func(context.Context) string) Enricher {
+	return func(ctx context.Context, e *Error) {
+		if e.RequestID == "" {
+			e.RequestID = RequestIDFromContext(ctx)
+		}
+		if userID != nil {
+			if id := userID(ctx); id != "" {
+				e.setField("user_id", id)
+			}
+		}
+		if tenant != nil {
+			if t := tenant(ctx); t != "" {
+				e.setField("tenant", t)
+			}
+		}
+		if deadline, ok := ctx.Deadline(); ok {
+			e.setField("deadline", deadline)
+		}
+		if err := ctx.Err(); err != nil {
+			e.setField("ctx_err", err.Error())
+		}
+	}
+}
+
+// setField sets the field key of e, a new error, in place.
+func (e *Error) setField(key string, value interface{}) {
+	if e.Fields == nil {
+		e.Fields = make(map[string]interface{})
+	}
+	e.Fields[key] = value
+}
+
+// RegisterEnricher adds fn to the enrichers applied to the errors created
+// with a context, by FromContext and NewErrorCtx:
+//
+//	resterror.RegisterEnricher(resterror.NewContextEnricher(auth.UserID, auth.Tenant))
+func RegisterEnricher(fn Enricher) {
+	updateDefaultRegistry(func(r *Registry) *Registry { return r.WithEnricher(fn) })
+}
+
+// enrich applies the enrichers of r to e, created in the scope of ctx.
+func (r *Registry) enrich(ctx context.Context, e *Error) {
+	for _, fn := range r.enrichers {
+		fn(ctx, e)
+	}
+}
+
+// FromContext is like E, but the Op defaults to the one carried by ctx, see
+// ContextWithOp, and the error is stamped by the registered enrichers, see
+// RegisterEnricher:
+//
+//	return resterror.FromContext(ctx, resterror.Kind(resterror.ENOTFOUND), resterror.Msg("User not found."))
+func FromContext(ctx context.Context, args ...interface{}) error {
+	err := newE(1, args)
+	var e *Error
+	if !errors.As(err, &e) {
+		return err
+	}
+	if e.Op == "" {
+		e.Op = OpFromContext(ctx)
+	}
+	DefaultRegistry().enrich(ctx, e)
+	return e
+}
@@ -0,0 +1,50 @@
+package error_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	resterror "github.com/truescotian/resterror"
+)
+
+type userKey struct{}
+
+func TestFromContext(t *testing.T) {
+	defer resterror.SetDefaultRegistry(resterror.DefaultRegistry())
+	userID := func(ctx context.Context) string {
+		id, _ := ctx.Value(userKey{}).(string)
+		return id
+	}
+	resterror.RegisterEnricher(resterror.NewContextEnricher(userID, nil))
+
+	deadline := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	ctx := resterror.ContextWithRequestID(context.WithValue(context.Background(), userKey{}, "u-42"), "req-1")
+	ctx = resterror.ContextWithOp(ctx, "GET /users/42")
+	ctx, cancel := context.WithDeadline(ctx, deadline)
+	defer cancel()
+
+	err := resterror.FromContext(ctx, resterror.Kind(resterror.ENOTFOUND), resterror.Msg("User not found."))
+	var e *resterror.Error
+	if !errors.As(err, &e) {
+		t.Fatalf("FromContext()=%v", err)
+	}
+	if e.Op != "GET /users/42" || e.RequestID != "req-1" {
+		t.Fatalf("Op=%q RequestID=%q", e.Op, e.RequestID)
+	}
+	if e.Fields["user_id"] != "u-42" || e.Fields["deadline"] != deadline || e.Fields["ctx_err"] != context.DeadlineExceeded.Error() {
+		t.Fatalf("Fields=%v", e.Fields)
+	}
+	if _, ok := e.Fields["tenant"]; ok {
+		t.Fatal("tenant is set without an extractor")
+	}
+
+	// NewErrorCtx is enriched too, and an explicit Op is kept by FromContext.
+	if e := resterror.NewErrorCtx(ctx, 500, "", resterror.EINTERNAL, nil); e.Fields["user_id"] != "u-42" {
+		t.Fatalf("NewErrorCtx() Fields=%v", e.Fields)
+	}
+	if err := resterror.FromContext(ctx, "UserService.FindUserByID"); resterror.OpTrace(err) != "UserService.FindUserByID" {
+		t.Fatalf("OpTrace()=%q", resterror.OpTrace(err))
+	}
+}
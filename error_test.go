@@ -1,7 +1,676 @@
 package error_test
 
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	resterror "github.com/truescotian/resterror"
+)
+
 func ExampleErrorMessage() {
-	if msg := ErrorMessage(err); msg != "" {
+	err := &resterror.Error{Kind: resterror.ENOTFOUND, Message: "User not found."}
+	if msg := resterror.ErrorMessage(err); msg != "" {
 		fmt.Printf("ERROR: %s\n", msg)
 	}
+	// Output: ERROR: User not found.
+}
+
+func TestCoerce(t *testing.T) {
+	e := &resterror.Error{Kind: resterror.ENOTFOUND, Status: 404, Message: "User not found."}
+	if got := resterror.Coerce(e); got != e {
+		t.Fatalf("Coerce(*Error)=%v, want same error", got)
+	}
+	if got := resterror.Coerce(fmt.Errorf("FindUserByID: %w", e)); got != e {
+		t.Fatalf("Coerce(wrapped *Error)=%v, want inner error", got)
+	}
+
+	plain := errors.New("connection refused")
+	got := resterror.Coerce(plain)
+	if got.Kind != resterror.EINTERNAL || got.Status != 500 || got.Err != plain {
+		t.Fatalf("Coerce(plain)=%#v", got)
+	}
+
+	if got := resterror.Coerce(nil); got != nil {
+		t.Fatalf("Coerce(nil)=%v", got)
+	}
+}
+
+func TestWalk(t *testing.T) {
+	root := &resterror.Error{Op: "findUser", Kind: resterror.ENOTFOUND}
+	err := &resterror.Error{
+		Op:  "UserService.FindUserByID",
+		Err: fmt.Errorf("query: %w", &resterror.Error{Op: "queryUser", Err: root}),
+	}
+
+	var ops []string
+	resterror.Walk(err, func(e *resterror.Error) bool {
+		ops = append(ops, e.Op)
+		return true
+	})
+	if got := strings.Join(ops, ","); got != "UserService.FindUserByID,queryUser,findUser" {
+		t.Fatalf("visited %q", got)
+	}
+
+	var visited int
+	var found *resterror.Error
+	resterror.Walk(err, func(e *resterror.Error) bool {
+		visited++
+		if e.Kind != "" || e.Op == "queryUser" {
+			found = e
+			return false
+		}
+		return true
+	})
+	if visited != 2 || found.Op != "queryUser" {
+		t.Fatalf("visited %d layers, stopped at %v", visited, found)
+	}
+}
+
+func TestAccessors(t *testing.T) {
+	if resterror.GetOp(nil) != "" || resterror.GetMessage(nil) != "" || resterror.GetStatus(nil) != 0 {
+		t.Fatal("expected zero values for a nil error")
+	}
+
+	err := &resterror.Error{
+		Op:  "UserService.CreateUser",
+		Err: &resterror.Error{Op: "insertUser", Kind: resterror.EINVALID, Status: 422, Message: "Username is required."},
+	}
+	if got := resterror.GetOp(err); got != "UserService.CreateUser" {
+		t.Fatalf("GetOp()=%q", got)
+	}
+	// The outermost error has no message or status of its own.
+	if got := resterror.GetMessage(err); got != "" {
+		t.Fatalf("GetMessage()=%q", got)
+	}
+	if got := resterror.GetStatus(err.Err); got != 422 {
+		t.Fatalf("GetStatus()=%d", got)
+	}
+	if got := resterror.GetMessage(fmt.Errorf("wrapped: %w", err.Err)); got != "Username is required." {
+		t.Fatalf("GetMessage()=%q", got)
+	}
+}
+
+func TestError(t *testing.T) {
+	cause := errors.New(`syntax error at or near "INSERT"`)
+	tests := []struct {
+		err  *resterror.Error
+		want string
+	}{
+		{&resterror.Error{Kind: resterror.ENOTFOUND, Message: "User not found."}, "<item_does_not_exist> User not found."},
+		{&resterror.Error{Op: "insertUser", Err: cause}, `insertUser: syntax error at or near "INSERT"`},
+		{&resterror.Error{Op: "insertUser", Kind: resterror.ECONFLICT, Err: cause}, `insertUser: <conflict> syntax error at or near "INSERT"`},
+		{&resterror.Error{Op: "insertUser", Kind: resterror.EINTERNAL, Err: cause}, `insertUser: <internal> syntax error at or near "INSERT"`},
+		{
+			&resterror.Error{Op: "UserService.CreateUser", Kind: resterror.EINTERNAL, Err: &resterror.Error{Op: "attachRole", Err: cause}},
+			`UserService.CreateUser: <internal> attachRole: syntax error at or near "INSERT"`,
+		},
+	}
+	for _, tt := range tests {
+		if got := tt.err.Error(); got != tt.want {
+			t.Errorf("Error()=%q, want %q", got, tt.want)
+		}
+	}
+}
+
+func TestConflictAndExists(t *testing.T) {
+	conflict := resterror.NewConflictError("PublishPost", "Post is archived.")
+	exists := resterror.NewExistsError("CreateUser", "Username is already in use.")
+
+	if conflict.Status != 409 || exists.Status != 409 {
+		t.Fatalf("statuses %d and %d, want 409", conflict.Status, exists.Status)
+	}
+	if resterror.StatusForKind(resterror.ECONFLICT) != 409 || resterror.StatusForKind(resterror.EEXIST) != 409 {
+		t.Fatal("ECONFLICT and EEXIST should both map to 409")
+	}
+	if !resterror.IsConflict(conflict) || resterror.IsExists(conflict) {
+		t.Fatal("conflict error cross-matched")
+	}
+	if !resterror.IsExists(&resterror.Error{Op: "UserService.CreateUser", Err: exists}) || resterror.IsConflict(exists) {
+		t.Fatal("exists error cross-matched")
+	}
+	if resterror.IsConflict(nil) || resterror.IsExists(nil) {
+		t.Fatal("nil error matched")
+	}
+}
+
+func TestSelfReferentialChain(t *testing.T) {
+	e := &resterror.Error{Op: "loop"}
+	e.Err = e
+
+	if got := resterror.ErrorKind(e); got != resterror.EINTERNAL {
+		t.Errorf("ErrorKind()=%q", got)
+	}
+	if got := resterror.ErrorMessage(e); got != resterror.MsgInternal {
+		t.Errorf("ErrorMessage()=%q", got)
+	}
+	if resterror.Is(resterror.ENOTFOUND, &resterror.Error{Kind: resterror.OTHER, Err: e}) {
+		t.Error("Is() matched")
+	}
+	// The 100 layers walked are the same consecutive op, printed once.
+	if got := e.Error(); got != "loop: " {
+		t.Errorf("Error()=%q", got)
+	}
+	a, b := &resterror.Error{Op: "a"}, &resterror.Error{Op: "b"}
+	a.Err, b.Err = b, a
+	if got := strings.Count(a.Error(), ": "); got != 100 {
+		t.Errorf("Error() printed %d layers", got)
+	}
+	if _, err := e.ResponseBody(); err != nil {
+		t.Errorf("ResponseBody()=%v", err)
+	}
+	resterror.OpTrace(e)
+	resterror.Cause(e)
+	resterror.Walk(e, func(*resterror.Error) bool { return true })
+
+	// The helpers matching causes like errors.Is and errors.As are capped
+	// too, wrapped or not.
+	defer resterror.SetDefaultRegistry(resterror.DefaultRegistry())
+	resterror.SetLogger(discard)
+	for _, err := range []error{e, fmt.Errorf("wrapped: %w", e)} {
+		if !resterror.ShouldTripBreaker(err) {
+			t.Error("ShouldTripBreaker()=false")
+		}
+		if resterror.IsRetryable(err) {
+			t.Error("IsRetryable()=true")
+		}
+		if err := resterror.AssertClientError(err); err != nil {
+			t.Errorf("AssertClientError()=%v", err)
+		}
+		if _, ok := resterror.PayloadAs[int](err); ok {
+			t.Error("PayloadAs() matched")
+		}
+		rec := httptest.NewRecorder()
+		resterror.Handle(func(http.ResponseWriter, *http.Request) error { return err }).
+			ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+		if rec.Code != http.StatusInternalServerError {
+			t.Errorf("Handle() status=%d", rec.Code)
+		}
+	}
+}
+
+func TestNewRangeError(t *testing.T) {
+	e := resterror.NewRangeError("ListUsers", "limit", 1, 100)
+	if e.Kind != resterror.EINVALID || e.Status != 422 || e.Op != "ListUsers" {
+		t.Fatalf("NewRangeError()=%#v", e)
+	}
+	if e.Message != "limit must be between 1 and 100." {
+		t.Fatalf("Message=%q", e.Message)
+	}
+	want := map[string]interface{}{"param": "limit", "min": 1, "max": 100}
+	if !reflect.DeepEqual(e.Details, want) {
+		t.Fatalf("Details=%v", e.Details)
+	}
+}
+
+func TestSameKind(t *testing.T) {
+	shallow := &resterror.Error{Kind: resterror.ENOTFOUND}
+	deep := &resterror.Error{Op: "UserService.FindUserByID", Err: &resterror.Error{Op: "findUser", Err: &resterror.Error{Kind: resterror.ENOTFOUND}}}
+	invalid := &resterror.Error{Kind: resterror.EINVALID}
+
+	tests := []struct {
+		a, b error
+		want bool
+	}{
+		{shallow, deep, true},
+		{shallow, invalid, false},
+		{errors.New("boom"), &resterror.Error{Kind: resterror.EINTERNAL}, true},
+		{nil, nil, true},
+		{nil, shallow, false},
+		{shallow, nil, false},
+	}
+	for _, tt := range tests {
+		if got := resterror.SameKind(tt.a, tt.b); got != tt.want {
+			t.Errorf("SameKind(%v, %v)=%v, want %v", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestWrapKind(t *testing.T) {
+	inner := &resterror.Error{Op: "findUser", Kind: resterror.ENOTFOUND, Status: 404, Message: "User not found."}
+	for _, err := range []error{inner, fmt.Errorf("query: %w", inner)} {
+		e := resterror.WrapKind("UserService.FindUserByID", err)
+		if e.Kind != resterror.ENOTFOUND || e.Status != 404 || e.Err != err || e.Op != "UserService.FindUserByID" {
+			t.Fatalf("WrapKind()=%#v", e)
+		}
+	}
+	for _, err := range []error{inner, fmt.Errorf("query: %w", inner)} {
+		if got := resterror.ErrorMessage(resterror.WrapKind("UserService.FindUserByID", err)); got != "User not found." {
+			t.Fatalf("ErrorMessage()=%q", got)
+		}
+	}
+
+	if e := resterror.WrapKind("op", errors.New("boom")); e.Kind != resterror.EINTERNAL || e.Status != 500 {
+		t.Fatalf("WrapKind(plain)=%#v", e)
+	}
+	if e := resterror.WrapKind("op", nil); e != nil {
+		t.Fatalf("WrapKind(nil)=%v", e)
+	}
+}
+
+func TestJoined(t *testing.T) {
+	validationErr := &resterror.Error{Kind: resterror.EINVALID, Status: 422, Message: "Username is required."}
+	internalErr := &resterror.Error{Op: "db.Insert", Kind: resterror.EINTERNAL, Err: errors.New("pq: connection refused")}
+
+	// Server errors take precedence over client errors, whatever the order.
+	for _, err := range []error{
+		errors.Join(validationErr, internalErr),
+		errors.Join(internalErr, validationErr),
+		&resterror.Error{Op: "CreateUser", Err: errors.Join(validationErr, internalErr)},
+	} {
+		if got := resterror.ErrorKind(err); got != resterror.EINTERNAL {
+			t.Fatalf("ErrorKind(%v)=%q", err, got)
+		}
+		if got := resterror.Coerce(err).Public().Status; got != http.StatusInternalServerError {
+			t.Fatalf("status of %v=%d", err, got)
+		}
+	}
+
+	// Between branches of the same class the first wins, and branches
+	// without an *Error are ignored.
+	notFoundErr := &resterror.Error{Kind: resterror.ENOTFOUND, Message: "User not found."}
+	err := errors.Join(errors.New("boom"), validationErr, notFoundErr)
+	if got := resterror.ErrorKind(err); got != resterror.EINVALID {
+		t.Fatalf("ErrorKind()=%q", got)
+	}
+	if got := resterror.ErrorMessage(err); got != "Username is required." {
+		t.Fatalf("ErrorMessage()=%q", got)
+	}
+	if got := resterror.ErrorKind(errors.Join(errors.New("boom"))); got != resterror.EINTERNAL {
+		t.Fatalf("ErrorKind() without *Error=%q", got)
+	}
+}
+
+func TestOp(t *testing.T) {
+	err := resterror.Op("CreateUser", func() error {
+		return &resterror.Error{Op: "db.Insert", Kind: resterror.EEXIST, Message: "Username is taken."}
+	})
+	if got := resterror.OpTrace(err); got != "CreateUser: db.Insert" {
+		t.Fatalf("OpTrace()=%q", got)
+	}
+	if resterror.ErrorKind(err) != resterror.EEXIST {
+		t.Fatalf("ErrorKind()=%q", resterror.ErrorKind(err))
+	}
+
+	if err := resterror.Op("CreateUser", func() error { return nil }); err != nil {
+		t.Fatalf("Op()=%v, want nil", err)
+	}
+}
+
+func TestError_RepeatedOps(t *testing.T) {
+	// Three consecutive identical ops, as in a recursive call, are collapsed.
+	err := &resterror.Error{Op: "walkTree", Err: &resterror.Error{Op: "walkTree", Err: &resterror.Error{
+		Op:      "walkTree",
+		Kind:    resterror.ENOTFOUND,
+		Message: "Node not found.",
+	}}}
+	if got, want := err.Error(), "walkTree: <item_does_not_exist> Node not found."; got != want {
+		t.Fatalf("Error()=%q, want %q", got, want)
+	}
+	if got := resterror.OpTrace(err); got != "walkTree" {
+		t.Fatalf("OpTrace()=%q", got)
+	}
+
+	// Non-consecutive repeats indicate re-entry and are preserved.
+	err = &resterror.Error{Op: "Sync", Ops: []string{"Sync"}, Err: &resterror.Error{Op: "Fetch", Err: &resterror.Error{
+		Op:  "Sync",
+		Err: errors.New("timeout"),
+	}}}
+	if got, want := err.Error(), "Sync: Fetch: Sync: timeout"; got != want {
+		t.Fatalf("Error()=%q, want %q", got, want)
+	}
+	if got := resterror.OpTrace(err); got != "Sync: Fetch: Sync" {
+		t.Fatalf("OpTrace()=%q", got)
+	}
+}
+
+func TestNewQuotaError(t *testing.T) {
+	defer resterror.SetDefaultRegistry(resterror.DefaultRegistry())
+
+	e := resterror.NewQuotaError("CreateProject", 10, 10)
+	if !resterror.IsQuota(&resterror.Error{Op: "POST /projects", Err: e}) {
+		t.Fatal("IsQuota()=false")
+	}
+	if resterror.IsQuota(&resterror.Error{Kind: resterror.EINVALID}) || resterror.IsQuota(nil) {
+		t.Fatal("IsQuota() matched")
+	}
+	want := map[string]interface{}{"limit": 10, "used": 10}
+	if !reflect.DeepEqual(e.Details, want) {
+		t.Fatalf("Details=%v", e.Details)
+	}
+	if pub := e.Public(); pub.Status != http.StatusTooManyRequests || pub.Message != "You have used 10 of the 10 allowed by your plan." {
+		t.Fatalf("Public()=%+v", pub)
+	}
+
+	resterror.RegisterKind(resterror.EQUOTA, http.StatusPaymentRequired, "")
+	if got := e.Public().Status; got != http.StatusPaymentRequired {
+		t.Fatalf("status after RegisterKind=%d", got)
+	}
+}
+
+func TestUnwrap(t *testing.T) {
+	sentinel := errors.New("sql: no rows in result set")
+	err := &resterror.Error{Op: "FindUser", Err: &resterror.Error{Op: "db.QueryRow", Kind: resterror.ENOTFOUND, Err: sentinel}}
+
+	if !errors.Is(err, sentinel) {
+		t.Fatal("errors.Is() doesn't see through *Error")
+	}
+	if errors.Unwrap(err) != err.Err {
+		t.Fatal("errors.Unwrap() doesn't return Err")
+	}
+
+	var pathErr *os.PathError
+	err = &resterror.Error{Op: "LoadConfig", Err: &os.PathError{Op: "open", Path: "config.json", Err: os.ErrNotExist}}
+	if !errors.As(err, &pathErr) || pathErr.Path != "config.json" {
+		t.Fatal("errors.As() doesn't see through *Error")
+	}
+	if !errors.Is(err, os.ErrNotExist) {
+		t.Fatal("errors.Is() doesn't see through *Error and *os.PathError")
+	}
+}
+
+func TestErrorKind_StdlibWrappers(t *testing.T) {
+	notFound := &resterror.Error{Op: "db.QueryRow", Kind: resterror.ENOTFOUND, Message: "User not found."}
+	err := &resterror.Error{Op: "FindUser", Err: fmt.Errorf("query user 42: %w", fmt.Errorf("scan: %w", notFound))}
+
+	if got := resterror.ErrorKind(err); got != resterror.ENOTFOUND {
+		t.Fatalf("ErrorKind()=%q", got)
+	}
+	if got := resterror.ErrorMessage(err); got != "User not found." {
+		t.Fatalf("ErrorMessage()=%q", got)
+	}
+	if got := resterror.ErrorKind(fmt.Errorf("handler: %w", err)); got != resterror.ENOTFOUND {
+		t.Fatalf("ErrorKind() of a wrapped *Error=%q", got)
+	}
+	if got := resterror.OpTrace(err); got != "FindUser: db.QueryRow" {
+		t.Fatalf("OpTrace()=%q", got)
+	}
+	if got := resterror.ErrorKind(fmt.Errorf("scan: %w", errors.New("boom"))); got != resterror.EINTERNAL {
+		t.Fatalf("ErrorKind() without *Error=%q", got)
+	}
+}
+
+func TestWrap(t *testing.T) {
+	if err := resterror.Wrap(nil, "FindUser"); err != nil {
+		t.Fatalf("Wrap(nil)=%v", err)
+	}
+	if err := resterror.Wrapf(nil, "FindUser", "id %d", 42); err != nil {
+		t.Fatalf("Wrapf(nil)=%v", err)
+	}
+
+	inner := &resterror.Error{Op: "db.QueryRow", Kind: resterror.ENOTFOUND, Message: "User not found."}
+	err := resterror.Wrap(inner, "FindUser")
+	if got := err.Error(); got != "FindUser: db.QueryRow: <item_does_not_exist> User not found." {
+		t.Fatalf("Error()=%q", got)
+	}
+
+	err = resterror.Wrapf(inner, "FindUser", "query user %d", 42)
+	if got := err.Error(); got != "FindUser: query user 42: db.QueryRow: <item_does_not_exist> User not found." {
+		t.Fatalf("Error()=%q", got)
+	}
+	if resterror.ErrorKind(err) != resterror.ENOTFOUND || resterror.ClientSafeMessage(err) != "User not found." {
+		t.Fatalf("kind=%q message=%q", resterror.ErrorKind(err), resterror.ClientSafeMessage(err))
+	}
+	if !errors.Is(err, inner) {
+		t.Fatal("errors.Is(Wrapf(inner), inner)=false")
+	}
+}
+
+func TestErrorf(t *testing.T) {
+	cause := errors.New("sql: no rows in result set")
+	e := resterror.Errorf(resterror.ENOTFOUND, "user %d: %w", 42, cause)
+	if e.Kind != resterror.ENOTFOUND || e.Message != "user 42" || e.Err != cause {
+		t.Fatalf("Errorf()=%#v", e)
+	}
+	if !errors.Is(e, cause) || e.Public().Status != http.StatusNotFound {
+		t.Fatalf("Errorf()=%#v", e)
+	}
+	if got := resterror.ClientSafeMessage(e); strings.Contains(got, "sql") {
+		t.Fatalf("ClientSafeMessage()=%q leaks the wrapped error", got)
+	}
+	if got := e.Error(); !strings.Contains(got, "sql: no rows in result set") {
+		t.Fatalf("Error()=%q, want the wrapped error", got)
+	}
+
+	e = resterror.Errorf(resterror.EINVALID, "limit must be positive, got %d", -1)
+	if e.Message != "limit must be positive, got -1" || e.Err != nil {
+		t.Fatalf("Errorf() without %%w=%#v", e)
+	}
+
+	other := errors.New("connection reset")
+	e = resterror.Errorf(resterror.EINTERNAL, "%w and %w", cause, other)
+	if !errors.Is(e, cause) || !errors.Is(e, other) || e.Message != "and" {
+		t.Fatalf("Errorf() with several %%w=%#v", e)
+	}
+}
+
+func TestJoined_First(t *testing.T) {
+	defer resterror.SetDefaultRegistry(resterror.DefaultRegistry())
+	resterror.SetJoinPolicy(resterror.JoinFirst)
+
+	validationErr := &resterror.Error{Kind: resterror.EINVALID, Message: "Username is required."}
+	internalErr := &resterror.Error{Op: "db.Insert", Kind: resterror.EINTERNAL, Err: errors.New("pq: connection refused")}
+	err := errors.Join(errors.New("boom"), validationErr, internalErr)
+
+	if got := resterror.ErrorKind(err); got != resterror.EINVALID {
+		t.Fatalf("ErrorKind()=%q", got)
+	}
+	if !resterror.Is(resterror.EINVALID, err) {
+		t.Fatal("Is(EINVALID) should match the first branch")
+	}
+	if got := resterror.ErrorMessage(err); got != "Username is required." {
+		t.Fatalf("ErrorMessage()=%q", got)
+	}
+
+	w := httptest.NewRecorder()
+	resterror.WriteError(w, err)
+	if w.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("status=%d", w.Code)
+	}
+}
+
+func TestJoined_RegistryPolicy(t *testing.T) {
+	r := resterror.NewRegistry().WithJoinPolicy(resterror.JoinFirst)
+
+	validationErr := &resterror.Error{Kind: resterror.EINVALID, Message: "Username is required."}
+	internalErr := &resterror.Error{Op: "db.Insert", Kind: resterror.EINTERNAL, Err: errors.New("pq: connection refused")}
+	err := errors.Join(validationErr, internalErr)
+
+	// The default registry keeps picking the server error.
+	if got := resterror.ErrorStatus(err); got != http.StatusInternalServerError {
+		t.Fatalf("ErrorStatus()=%d", got)
+	}
+	if got := r.ErrorStatus(err); got != http.StatusUnprocessableEntity {
+		t.Fatalf("Registry.ErrorStatus()=%d", got)
+	}
+	if got := r.ClientSafeMessage(err); got != "Username is required." {
+		t.Fatalf("Registry.ClientSafeMessage()=%q", got)
+	}
+	if pub := r.Public(&resterror.Error{Err: err}); pub.Kind != resterror.EINVALID || pub.Status != http.StatusUnprocessableEntity {
+		t.Fatalf("Registry.Public()=%#v", pub)
+	}
+
+	w := httptest.NewRecorder()
+	r.WriteError(w, err)
+	want := `{"kind":"invalid","status":422,"message":"Username is required."}`
+	if w.Code != http.StatusUnprocessableEntity || w.Body.String() != want {
+		t.Fatalf("status=%d body=%s, want %s", w.Code, w.Body, want)
+	}
+
+	// The op trace, fingerprint and trailers come from the same branch.
+	dump := r.DebugDump(httptest.NewRequest(http.MethodGet, "/users", nil), err)
+	if strings.Contains(dump, "op_trace: db.Insert") || !strings.Contains(dump, "kind: invalid") {
+		t.Fatalf("DebugDump()=%s", dump)
+	}
+	for _, attr := range r.LogAttrs(err) {
+		if attr.Key == "fingerprint" && attr.Value.String() != validationErr.Fingerprint() {
+			t.Fatalf("fingerprint=%s, want that of the first branch", attr.Value)
+		}
+	}
+	w = httptest.NewRecorder()
+	r.WriteErrorTrailer(w, err)
+	if got := w.Header().Get(http.TrailerPrefix + resterror.TrailerKind); got != resterror.EINVALID {
+		t.Fatalf("trailer kind=%q", got)
+	}
+}
+
+func TestShorthandConstructors(t *testing.T) {
+	cause := errors.New("pq: connection refused")
+	tests := []struct {
+		err    *resterror.Error
+		kind   string
+		status int
+	}{
+		{resterror.NotFound("FindUser", "User not found."), resterror.ENOTFOUND, http.StatusNotFound},
+		{resterror.Invalid("CreateUser", "Username is required."), resterror.EINVALID, http.StatusUnprocessableEntity},
+		{resterror.Conflict("CancelOrder", "Order was shipped."), resterror.ECONFLICT, http.StatusConflict},
+		{resterror.Forbidden("DeleteUser", "Only admins can delete users."), resterror.PERMISSION, http.StatusForbidden},
+		{resterror.Internal("FindUser", cause), resterror.EINTERNAL, http.StatusInternalServerError},
+	}
+	for _, tt := range tests {
+		if tt.err.Kind != tt.kind || tt.err.Status != tt.status {
+			t.Errorf("%s: kind=%q status=%d, want %q %d", tt.err.Op, tt.err.Kind, tt.err.Status, tt.kind, tt.status)
+		}
+	}
+	if e := resterror.Internal("FindUser", cause); !errors.Is(e, cause) || resterror.ClientSafeMessage(e) != resterror.MsgInternal {
+		t.Fatalf("Internal()=%v", e)
+	}
+}
+
+func TestKindPredicates(t *testing.T) {
+	wrap := func(e *resterror.Error) error {
+		return fmt.Errorf("handler: %w", &resterror.Error{Op: "UserService.UpdateUser", Err: e})
+	}
+	tests := []struct {
+		name string
+		is   func(error) bool
+		kind string
+	}{
+		{"IsNotFound", resterror.IsNotFound, resterror.ENOTFOUND},
+		{"IsInvalid", resterror.IsInvalid, resterror.EINVALID},
+		{"IsPermission", resterror.IsPermission, resterror.PERMISSION},
+		{"IsInternal", resterror.IsInternal, resterror.EINTERNAL},
+		{"IsConflict", resterror.IsConflict, resterror.ECONFLICT},
+	}
+	for _, tt := range tests {
+		if !tt.is(wrap(&resterror.Error{Kind: tt.kind})) {
+			t.Errorf("%s(%s)=false", tt.name, tt.kind)
+		}
+		if tt.is(wrap(&resterror.Error{Kind: resterror.EEXIST})) || tt.is(nil) {
+			t.Errorf("%s matched", tt.name)
+		}
+	}
+	if resterror.IsNotFound(&resterror.Error{Kind: resterror.ENOTFOUND_ROUTE}) {
+		t.Fatal("IsNotFound(ENOTFOUND_ROUTE)=true")
+	}
+	if !resterror.IsInternal(errors.New("boom")) {
+		t.Fatal("IsInternal(plain)=false")
+	}
+}
+
+func TestClone(t *testing.T) {
+	sentinel := &resterror.Error{
+		Kind:    resterror.ENOTFOUND,
+		Message: "User not found.",
+		Details: map[string]interface{}{"resource": "user"},
+		Ops:     []string{"UserService.FindUserByID"},
+	}
+	sentinel = sentinel.WithField("table", "users")
+
+	c := sentinel.Clone()
+	c.Details["resource"] = "account"
+	c.Fields["table"] = "accounts"
+	c.Ops[0] = "AccountService.FindAccountByID"
+	if sentinel.Details["resource"] != "user" || sentinel.Fields["table"] != "users" || sentinel.Ops[0] != "UserService.FindUserByID" {
+		t.Fatalf("Clone() shares state with the original: %+v", sentinel)
+	}
+}
+
+func TestWith_CopyOnWrite(t *testing.T) {
+	sentinel := &resterror.Error{Kind: resterror.ENOTFOUND, Message: "User not found."}
+
+	// Annotating a shared sentinel concurrently is safe, and leaves it
+	// unchanged.
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			e := sentinel.WithHint("Pass ?email= to search by email.").WithField("attempt", i).WithLocation("/users").
+				MarkInternal("attempt").MarkSensitive("attempt")
+			if e == sentinel || e.Fields["attempt"] != i {
+				t.Errorf("With methods returned %+v", e)
+			}
+		}(i)
+	}
+	wg.Wait()
+	if sentinel.Hint != "" || sentinel.Fields != nil || sentinel.Location != "" {
+		t.Fatalf("sentinel was modified: %+v", sentinel)
+	}
+
+	// Marked keys stay on the copy.
+	sentinel = &resterror.Error{Kind: resterror.ENOTFOUND, Details: map[string]interface{}{"ref": "gw_8f3a"}}
+	if body, _ := sentinel.MarkInternal("ref").ResponseBody(); strings.Contains(string(body), "gw_8f3a") {
+		t.Fatalf("body=%s", body)
+	}
+	if body, _ := sentinel.ResponseBody(); !strings.Contains(string(body), "gw_8f3a") {
+		t.Fatalf("sentinel was marked: %s", body)
+	}
+}
+
+// pkgCause is the Cause function of github.com/pkg/errors.
+func pkgCause(err error) error {
+	type causer interface {
+		Cause() error
+	}
+	for err != nil {
+		cause, ok := err.(causer)
+		if !ok {
+			break
+		}
+		err = cause.Cause()
+	}
+	return err
+}
+
+func TestError_RepeatedKinds(t *testing.T) {
+	inner := &resterror.Error{Op: "queryUser", Kind: resterror.ENOTFOUND, Message: "User not found."}
+	err := resterror.WrapKind("UserService.FindUserByID", inner)
+	if got, want := err.Error(), "UserService.FindUserByID: <item_does_not_exist> queryUser: User not found."; got != want {
+		t.Fatalf("Error()=%q, want %q", got, want)
+	}
+
+	// A different kind in between is printed, and the repeat after it too.
+	err = &resterror.Error{Op: "Import", Kind: resterror.EINVALID, Err: &resterror.Error{
+		Op:   "parseRow",
+		Kind: resterror.EINTERNAL,
+		Err:  &resterror.Error{Op: "parseDate", Kind: resterror.EINVALID, Message: "Bad date."},
+	}}
+	if got, want := err.Error(), "Import: <invalid> parseRow: <internal> parseDate: <invalid> Bad date."; got != want {
+		t.Fatalf("Error()=%q, want %q", got, want)
+	}
+}
+
+func TestError_Cause(t *testing.T) {
+	root := errors.New("pq: connection refused")
+	err := &resterror.Error{Op: "CreateUser", Err: &resterror.Error{Op: "insertUser", Kind: resterror.EINTERNAL, Err: root}}
+	if got := pkgCause(err); got != root {
+		t.Fatalf("pkg/errors Cause()=%v, want %v", got, root)
+	}
+
+	leaf := &resterror.Error{Op: "findUser", Kind: resterror.ENOTFOUND}
+	got := pkgCause(&resterror.Error{Op: "FindUser", Err: leaf})
+	if got == nil || !errors.Is(got, leaf) || got.Error() != leaf.Error() {
+		t.Fatalf("pkg/errors Cause()=%v, want %v", got, leaf)
+	}
+	var e *resterror.Error
+	if !errors.As(got, &e) || e != leaf {
+		t.Fatalf("errors.As(Cause()) = %v", e)
+	}
 }
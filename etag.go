@@ -0,0 +1,41 @@
+package error
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// etag returns the ETag of the response of e, derived from its body, or an
+// empty string if r doesn't emit ETags, see SetETags.
+func (r *Registry) etag(e *Error) string {
+	if !r.etags {
+		return ""
+	}
+	body, err := r.ResponseBody(e)
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256(body)
+	return fmt.Sprintf(`"%x"`, sum[:8])
+}
+
+// etagMatches reports whether the If-None-Match header of req matches etag,
+// using the weak comparison of RFC 9110. Only GET and HEAD requests are
+// considered, and "*" never matches: it asks whether the resource exists,
+// which an error response doesn't tell.
+func etagMatches(req *http.Request, etag string) bool {
+	if req.Method != http.MethodGet && req.Method != http.MethodHead {
+		return false
+	}
+	for _, v := range req.Header.Values("If-None-Match") {
+		for _, tag := range strings.Split(v, ",") {
+			tag = strings.TrimPrefix(strings.TrimSpace(tag), "W/")
+			if tag == etag {
+				return true
+			}
+		}
+	}
+	return false
+}
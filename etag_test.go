@@ -0,0 +1,67 @@
+package error_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	resterror "github.com/truescotian/resterror"
+)
+
+func TestHandler_IfNoneMatch(t *testing.T) {
+	notFound := func(w http.ResponseWriter, r *http.Request) error {
+		return &resterror.Error{Kind: resterror.ENOTFOUND, Status: 404, Message: "User not found."}
+	}
+	h := &resterror.Handler{Fn: notFound, Logger: discard, Registry: resterror.NewRegistry().WithETags(true)}
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/users/42", nil))
+	etag := rec.Header().Get("ETag")
+	if rec.Code != http.StatusNotFound || etag == "" || rec.Body.Len() == 0 {
+		t.Fatalf("status=%d ETag=%q body=%q", rec.Code, etag, rec.Body)
+	}
+
+	for _, inm := range []string{etag, "W/" + etag, `"other", ` + etag} {
+		req := httptest.NewRequest(http.MethodGet, "/users/42", nil)
+		req.Header.Set("If-None-Match", inm)
+		rec = httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		if rec.Code != http.StatusNotModified || rec.Body.Len() != 0 || rec.Header().Get("ETag") != etag {
+			t.Fatalf("If-None-Match %s: status=%d ETag=%q body=%q", inm, rec.Code, rec.Header().Get("ETag"), rec.Body)
+		}
+	}
+
+	// A stale ETag gets the full response.
+	req := httptest.NewRequest(http.MethodGet, "/users/42", nil)
+	req.Header.Set("If-None-Match", `"stale"`)
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if rec.Code != http.StatusNotFound || rec.Body.Len() == 0 {
+		t.Fatalf("status=%d body=%q", rec.Code, rec.Body)
+	}
+
+	// A wildcard or a request other than GET and HEAD gets the full response.
+	for _, tc := range []struct{ method, inm string }{
+		{http.MethodGet, "*"},
+		{http.MethodPut, "*"},
+		{http.MethodPut, etag},
+	} {
+		req := httptest.NewRequest(tc.method, "/users/42", nil)
+		req.Header.Set("If-None-Match", tc.inm)
+		rec = httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		if rec.Code != http.StatusNotFound || rec.Body.Len() == 0 {
+			t.Fatalf("%s If-None-Match %s: status=%d body=%q", tc.method, tc.inm, rec.Code, rec.Body)
+		}
+	}
+
+	// Without ETags the behavior is unchanged.
+	h = &resterror.Handler{Fn: notFound, Logger: discard}
+	req = httptest.NewRequest(http.MethodGet, "/users/42", nil)
+	req.Header.Set("If-None-Match", etag)
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if rec.Code != http.StatusNotFound || rec.Header().Get("ETag") != "" {
+		t.Fatalf("status=%d ETag=%q", rec.Code, rec.Header().Get("ETag"))
+	}
+}
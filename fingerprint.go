@@ -0,0 +1,23 @@
+package error
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"strings"
+)
+
+// Fingerprint returns a hash identifying the logical error e is an
+// occurrence of, so that error trackers and log aggregators can group them.
+//
+// Only the kind and the op trace of e are hashed: messages, details and
+// causes vary between occurrences, e.g. with the ID of a missing user, and
+// are deliberately left out.
+func (e *Error) Fingerprint() string {
+	return DefaultRegistry().fingerprint(e)
+}
+
+// fingerprint is like Error.Fingerprint but resolves e with r.
+func (r *Registry) fingerprint(e *Error) string {
+	sum := sha256.Sum256([]byte(r.errorKind(e) + "\x00" + strings.Join(r.ops(e), ": ")))
+	return fmt.Sprintf("%x", sum[:8])
+}
@@ -0,0 +1,45 @@
+package error_test
+
+import (
+	"errors"
+	"log/slog"
+	"testing"
+
+	resterror "github.com/truescotian/resterror"
+)
+
+func TestFingerprint(t *testing.T) {
+	findUser := func(id string) *resterror.Error {
+		return &resterror.Error{
+			Op:  "UserService.FindUserByID",
+			Err: &resterror.Error{Op: "queryUser", Kind: resterror.ENOTFOUND, Message: "User " + id + " not found.", Err: errors.New("sql: no rows")},
+		}
+	}
+
+	a, b := findUser("1").Fingerprint(), findUser("2").Fingerprint()
+	if a != b {
+		t.Fatalf("occurrences have different fingerprints: %s, %s", a, b)
+	}
+	if len(a) != 16 {
+		t.Fatalf("Fingerprint()=%q", a)
+	}
+
+	other := &resterror.Error{Op: "UserService.DeleteUser", Err: &resterror.Error{Op: "queryUser", Kind: resterror.ENOTFOUND}}
+	if other.Fingerprint() == a {
+		t.Fatal("different ops have the same fingerprint")
+	}
+	invalid := &resterror.Error{Op: "UserService.FindUserByID", Err: &resterror.Error{Op: "queryUser", Kind: resterror.EINVALID}}
+	if invalid.Fingerprint() == a {
+		t.Fatal("different kinds have the same fingerprint")
+	}
+
+	for _, attr := range resterror.LogAttrs(findUser("3")) {
+		if attr.Key == "fingerprint" {
+			if attr.Value.Kind() != slog.KindString || attr.Value.String() != a {
+				t.Fatalf("fingerprint=%v, want %s", attr.Value, a)
+			}
+			return
+		}
+	}
+	t.Fatal("fingerprint isn't logged")
+}
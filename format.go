@@ -0,0 +1,78 @@
+package error
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// Format implements fmt.Formatter.
+//
+// %s and %v print the single-line form of Error, %q quotes it, and %#v
+// prints the Go syntax of the fields like for any struct. %+v prints
+// a multi-line view of the chain for debugging, one line per layer indented
+// by its depth, with its ops, kind, status, message and time, followed by its
+// call stack if captured (see Frames), and the wrapped cause last:
+//
+//	FindUser
+//	  db.QueryRow <item_does_not_exist> status=404 message="User not found."
+//	    cause: sql: no rows in result set
+func (e *Error) Format(f fmt.State, verb rune) {
+	switch {
+	case verb == 'v' && f.Flag('+'):
+		e.formatVerbose(f)
+	case verb == 'v' && f.Flag('#'):
+		s := fmt.Sprintf("%#v", (*rawError)(e))
+		io.WriteString(f, strings.Replace(s, "rawError", "Error", 1))
+	case verb == 'q':
+		fmt.Fprintf(f, "%q", e.Error())
+	case verb == 'v' || verb == 's':
+		io.WriteString(f, e.Error())
+	default:
+		fmt.Fprintf(f, "%%!%c(*error.Error=%s)", verb, e.Error())
+	}
+}
+
+// rawError has the fields of Error without its Format method, so that %#v
+// prints them.
+type rawError Error
+
+// formatVerbose writes the %+v view of e to w.
+func (e *Error) formatVerbose(w io.Writer) {
+	for depth := 0; depth < maxDepth; depth++ {
+		indent := strings.Repeat("  ", depth)
+		line := strings.Join(e.layerOps(), ": ")
+		if line == "" {
+			line = "-"
+		}
+		if e.Kind != "" {
+			line += " <" + e.Kind + ">"
+		}
+		if e.Status != 0 {
+			line += fmt.Sprintf(" status=%d", e.Status)
+		}
+		if e.Message != "" {
+			line += fmt.Sprintf(" message=%q", e.Message)
+		}
+		if !e.Time.IsZero() {
+			line += " time=" + e.Time.Format(time.RFC3339Nano)
+		}
+		if depth > 0 {
+			io.WriteString(w, "\n")
+		}
+		io.WriteString(w, indent+line)
+		for _, frame := range e.Frames() {
+			fmt.Fprintf(w, "\n%s    at %s (%s:%d)", indent, frame.Function, frame.File, frame.Line)
+		}
+
+		next, ok := e.Err.(*Error)
+		if !ok {
+			if e.Err != nil {
+				fmt.Fprintf(w, "\n%s  cause: %v", indent, e.Err)
+			}
+			return
+		}
+		e = next
+	}
+}
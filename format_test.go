@@ -0,0 +1,63 @@
+package error_test
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	resterror "github.com/truescotian/resterror"
+)
+
+func TestFormat(t *testing.T) {
+	err := &resterror.Error{Op: "FindUser", Err: &resterror.Error{
+		Op:      "db.QueryRow",
+		Kind:    resterror.ENOTFOUND,
+		Status:  404,
+		Message: "User not found.",
+		Err:     errors.New("sql: no rows in result set"),
+	}}
+
+	for _, verb := range []string{"%v", "%s"} {
+		if got := fmt.Sprintf(verb, err); got != err.Error() {
+			t.Fatalf("%s=%q", verb, got)
+		}
+	}
+	if got, want := fmt.Sprintf("%q", err), fmt.Sprintf("%q", err.Error()); got != want {
+		t.Fatalf("%%q=%s, want %s", got, want)
+	}
+	if got := fmt.Sprintf("%#v", err.Err); !strings.HasPrefix(got, `&error.Error{`) || !strings.Contains(got, `Kind:"item_does_not_exist"`) || !strings.Contains(got, `Op:"db.QueryRow"`) {
+		t.Fatalf("%%#v=%s", got)
+	}
+	if got, want := fmt.Sprintf("%#v", (*resterror.Error)(nil)), "(*error.Error)(nil)"; got != want {
+		t.Fatalf("%%#v=%s, want %s", got, want)
+	}
+
+	want := `FindUser
+  db.QueryRow <item_does_not_exist> status=404 message="User not found."
+    cause: sql: no rows in result set`
+	if got := fmt.Sprintf("%+v", err); got != want {
+		t.Fatalf("%%+v=\n%s\nwant\n%s", got, want)
+	}
+
+	// Layers without ops nor cause.
+	err = &resterror.Error{Err: &resterror.Error{Kind: resterror.EINVALID}}
+	if got, want := fmt.Sprintf("%+v", err), "-\n  - <invalid>"; got != want {
+		t.Fatalf("%%+v=%q, want %q", got, want)
+	}
+}
+
+func TestFormat_FramesAndTime(t *testing.T) {
+	defer resterror.SetDefaultRegistry(resterror.DefaultRegistry())
+	resterror.SetClock(func() time.Time { return time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC) })
+
+	err := resterror.NewError("FindUser", 500, "", resterror.EINTERNAL, errors.New("boom"))
+	got := fmt.Sprintf("%+v", err)
+	if !strings.HasPrefix(got, "FindUser <internal> status=500 time=2024-03-01T12:00:00Z\n    at ") || !strings.Contains(got, ".TestFormat_FramesAndTime (") {
+		t.Fatalf("%%+v=\n%s", got)
+	}
+	if !strings.HasSuffix(got, "\n  cause: boom") {
+		t.Fatalf("%%+v=\n%s", got)
+	}
+}
@@ -1,11 +1,329 @@
 package error
 
 import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net"
 	"net/http"
+	"sort"
+	"strings"
+	"syscall"
+	"time"
 )
 
-// Wrapper for handler functions.
-type rootHandler func(http.ResponseWriter, *http.Request) error
+// Handler implements the http.Handler interface for handler functions which
+// return an error. The error is logged and written to the client, see
+// WriteError.
+type Handler struct {
+	// Fn handles the request.
+	Fn func(http.ResponseWriter, *http.Request) error
+
+	// Logger logs the errors returned by Fn.
+	// Defaults to the logger of Registry, see SetLogger.
+	Logger *slog.Logger
+
+	// ClientErrorLevel is the level client errors (4xx) are logged at, so
+	// that routine errors such as 404s can be filtered out. Server errors
+	// (5xx) are logged at slog.LevelError. Errors with a Severity are logged
+	// at its level instead.
+	// Defaults to slog.LevelInfo.
+	ClientErrorLevel slog.Level
+
+	// OnError, if set, is called with every error reported by Fn, e.g. to
+	// send it to an error tracker.
+	OnError func(*http.Request, error)
+
+	// Metrics, if set, receives metrics about the errors returned by Fn,
+	// including the latency of the request if it implements LatencyObserver.
+	Metrics MetricsSink
+
+	// Registry resolves and serializes the errors.
+	// Defaults to DefaultRegistry().
+	Registry *Registry
+
+	// CompressThreshold, when positive, makes the handler gzip error bodies
+	// larger than that many bytes for clients accepting gzip, such as the
+	// bodies of errors with many causes. Smaller bodies aren't worth the
+	// overhead and are left uncompressed. Every response then varies on
+	// Accept-Encoding, and a compressed one gets an ETag of its own.
+	CompressThreshold int
+
+	// Streaming declares the error trailers up front, for handlers streaming
+	// their response. If Fn fails after the headers were sent, the error is
+	// written in the trailers instead, see WriteErrorTrailer.
+	Streaming bool
+
+	// IgnoreDisconnects makes the handler skip errors caused by the client
+	// closing the connection (context canceled, broken pipe...). They are
+	// logged at debug level only and never reported to OnError, since there
+	// is nothing to fix and no one to respond to.
+	IgnoreDisconnects bool
+
+	// IDGenerator generates the ID of requests which don't carry a valid
+	// X-Request-ID header. The ID is sent back in the X-Request-ID header,
+	// logged with errors, and available to Fn with RequestIDFromContext.
+	// Defaults to UUIDGenerator.
+	IDGenerator IDGenerator
+
+	// IncidentIDs makes server errors (5xx) carry an incident ID in their
+	// body, which clients can quote to support. The ID is the request ID,
+	// and the DebugDump of the request is logged with it, so the details of
+	// the error stay server-side.
+	IncidentIDs bool
+
+	// RecoverPanics makes the handler recover from panics in Fn, and handle
+	// them as internal errors. http.ErrAbortHandler is re-panicked so the
+	// server still aborts the response.
+	RecoverPanics bool
+
+	// Problems makes the handler write errors as RFC 9457 problem details,
+	// with the application/problem+json content type, instead of the error
+	// body of WriteError. The instance of the problem is the path of the
+	// request. See WriteProblem.
+	Problems bool
+
+	// Strict logs the errors returned by Fn which aren't a ClientError, such
+	// as a bare errors.New, at error level since they are bugs, see
+	// AssertClientError. They are written as internal errors either way.
+	Strict bool
+}
+
+// Handle returns a handler for fn with everything needed in one line: panics
+// are recovered, errors are normalized with Coerce, logged and written with
+// the default registry.
+//
+//	http.Handle("/users/", resterror.Handle(serveUsers))
+//
+// Use a Handler for more control.
+func Handle(fn func(http.ResponseWriter, *http.Request) error) http.Handler {
+	return &Handler{Fn: fn, RecoverPanics: true, IgnoreDisconnects: true}
+}
+
+// ServeHTTP calls h.Fn and handles the error it returns, if any.
+//
+// The errors recorded on the request context with AddError are written as
+// the causes of the returned error.
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	id := requestID(h.idGenerator(), r)
+	w.Header().Set(HeaderRequestID, id)
+	r = r.WithContext(ContextWithRequestID(withCollector(r.Context()), id))
+	if h.Streaming {
+		declareErrorTrailers(w)
+	}
+	rw := &responseWriter{ResponseWriter: w}
+	start := time.Now()
+	err := h.call(rw, r)
+	if err == nil {
+		return
+	}
+	elapsed := time.Since(start)
+
+	if h.IgnoreDisconnects && isDisconnect(err) {
+		h.logger().LogAttrs(r.Context(), slog.LevelDebug, "client disconnected", h.logAttrs(err, id)...)
+		return
+	}
+
+	if h.Strict {
+		if aerr := AssertClientError(err); aerr != nil {
+			h.logger().LogAttrs(r.Context(), slog.LevelError, "unhandled error type", slog.String("error", aerr.Error()), slog.String("request_id", id))
+		}
+	}
+	h.logger().LogAttrs(r.Context(), h.level(err), "an error occurred", h.logAttrs(err, id)...)
+	if h.OnError != nil {
+		h.OnError(r, err)
+	}
+	e := h.registry().coerce(err)
+	status := h.registry().ErrorStatus(e)
+	observeError(h.Metrics, h.registry().errorKind(e), status, elapsed)
+
+	incident := h.IncidentIDs && status >= 500
+	if incident {
+		h.logger().LogAttrs(r.Context(), slog.LevelError, "incident",
+			slog.String("incident_id", id),
+			slog.String("dump", h.registry().DebugDump(r, err)),
+		)
+	}
+
+	// The status was already sent, it's too late to write the error.
+	if rw.wroteHeader {
+		if h.Streaming {
+			h.registry().WriteErrorTrailer(w, err)
+		}
+		return
+	}
+	if causes := CollectedErrors(r.Context()); len(causes) > 0 {
+		err = &Error{Err: h.registry().coerce(err), Causes: causes}
+	}
+	if incident {
+		err = &Error{Err: h.registry().coerce(err), IncidentID: id}
+	}
+	compress := h.CompressThreshold > 0 && acceptsGzip(r)
+	if h.CompressThreshold > 0 {
+		w.Header().Add("Vary", "Accept-Encoding")
+	}
+	if etag := h.registry().etag(h.registry().coerce(err)); etag != "" {
+		if compress && etagMatches(r, gzipETag(etag)) {
+			etag = gzipETag(etag)
+		}
+		if etagMatches(r, etag) {
+			w.Header().Set("ETag", etag)
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+	}
+	if compress {
+		gz := &gzipWriter{ResponseWriter: w, threshold: h.CompressThreshold}
+		defer gz.close()
+		w = gz
+	}
+	if h.Problems {
+		h.registry().writeProblem(w, err, r.URL.Path)
+		return
+	}
+	h.registry().WriteError(w, err)
+}
+
+// call calls h.Fn, recovering from its panics if h.RecoverPanics is set.
+func (h *Handler) call(w http.ResponseWriter, r *http.Request) (err error) {
+	if h.RecoverPanics {
+		defer func() {
+			if v := recover(); v != nil {
+				if v == http.ErrAbortHandler {
+					panic(v)
+				}
+				err = NewError(r.Method+" "+r.URL.Path, http.StatusInternalServerError, "", EINTERNAL, fmt.Errorf("panic: %v", v))
+			}
+		}()
+	}
+	return h.Fn(w, r)
+}
+
+func (h *Handler) registry() *Registry {
+	if h.Registry != nil {
+		return h.Registry
+	}
+	return DefaultRegistry()
+}
+
+func (h *Handler) idGenerator() IDGenerator {
+	if h.IDGenerator != nil {
+		return h.IDGenerator
+	}
+	return UUIDGenerator{}
+}
+
+// logAttrs returns the attributes err is logged with, see LogAttrs. The ID
+// of the request is added unless err carries one already.
+func (h *Handler) logAttrs(err error, requestID string) []slog.Attr {
+	attrs := h.registry().LogAttrs(err)
+	if h.registry().errorRequestID(err) == "" {
+		attrs = append(attrs, slog.String("request_id", requestID))
+	}
+	return attrs
+}
+
+// level returns the level err is logged at: the level of its severity if
+// set, see SeverityOf, otherwise a level derived from its status.
+func (h *Handler) level(err error) slog.Level {
+	if s := h.registry().severityOf(err); s != SeverityDefault {
+		return s.Level()
+	}
+	if h.registry().ErrorStatus(h.registry().coerce(err)) >= 500 {
+		return slog.LevelError
+	}
+	return h.ClientErrorLevel
+}
+
+func (h *Handler) logger() *slog.Logger {
+	if h.Logger != nil {
+		return h.Logger
+	}
+	return h.registry().log()
+}
+
+// WriteError writes err to w as a JSON response.
+//
+// err is normalized with Coerce, so errors of unknown provenance are
+// written as internal errors without leaking their message.
+//
+// Statuses which forbid a body, 204 and 304, are written with their headers
+// only, without a body nor a Content-Type.
+func WriteError(w http.ResponseWriter, err error) {
+	DefaultRegistry().WriteError(w, err)
+}
+
+// WriteError is like the package-level WriteError but resolves err with r.
+func (r *Registry) WriteError(w http.ResponseWriter, err error) {
+	e := r.coerce(err)
+	body, err := r.ResponseBody(e)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	status, headers := r.ResponseHeaders(e)
+	if !bodyAllowed(status) {
+		delete(headers, "Content-Type")
+	}
+	for k, v := range headers {
+		w.Header().Set(k, v)
+	}
+	w.WriteHeader(status)
+	if bodyAllowed(status) {
+		w.Write(body)
+	}
+}
+
+// bodyAllowed reports whether a response of the given status may have a
+// body. 204 and 304 responses must not.
+func bodyAllowed(status int) bool {
+	return status != http.StatusNoContent && status != http.StatusNotModified
+}
+
+// NotFoundHandler returns a handler writing an ENOTFOUND_ROUTE error, to be
+// used as the not found handler of a router. Like ENOTFOUND it is a 404, but
+// the distinct kind lets dashboards tell a missing route, possibly a bad
+// deploy, from a missing resource.
+func NotFoundHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		WriteError(w, &Error{
+			Kind:    ENOTFOUND_ROUTE,
+			Status:  http.StatusNotFound,
+			Message: MsgNotFound,
+		})
+	})
+}
+
+// MethodNotAllowedHandler returns a handler writing a MethodNotAllowed error
+// with an Allow header listing the allowed methods, to be used as the method
+// not allowed handler of a router.
+func MethodNotAllowedHandler(allowed ...string) http.Handler {
+	methods := make([]string, len(allowed))
+	for i, m := range allowed {
+		methods[i] = strings.ToUpper(m)
+	}
+	sort.Strings(methods)
+	allow := strings.Join(methods, ", ")
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Allow", allow)
+		WriteError(w, &Error{
+			Kind:    MethodNotAllowed,
+			Status:  http.StatusMethodNotAllowed,
+			Message: MsgMethodNotAllowed,
+		})
+	})
+}
+
+// isDisconnect reports whether err was caused by the client going away.
+func isDisconnect(err error) bool {
+	cause := Cause(err)
+	return is(cause, context.Canceled) ||
+		is(cause, syscall.EPIPE) ||
+		is(cause, syscall.ECONNRESET) ||
+		is(cause, net.ErrClosed)
+}
 
 /*
 func testHandler(w http.ResponseWriter, r *http.Request) error {
@@ -20,20 +338,9 @@ func testHandler(w http.ResponseWriter, r *http.Request) error {
 		}
 	}
 
-	body, err := ioutil.ReadAll(r.Body) // read request body.
-	if err != nil {
-		return fmt.Errorf("Request body read error : %v", err)
-	}
-
 	// Parse body as json.
-	if err := json.Unmarshal(body, &schema); err != nil {
-		return &Error{
-			Kind:    EPARSE,
-			Status:  400,
-			Message: "Unable to marshal resource",
-			Op:      op,
-			Err:     err,
-		}
+	if err := DecodeJSON(r, &schema); err != nil {
+		return &Error{Op: op, Err: err}
 	}
 
 	ok, err := loginUser("username", "password")
@@ -53,42 +360,11 @@ func testHandler(w http.ResponseWriter, r *http.Request) error {
 	return nil
 }
 
-// Implement the http.Handler interface.
-func (fn rootHandler) ServeHttp(w http.ResponseWriter, r *http.Request) {
-	err := fn(w, r) // Call handler function.
-	if err == nil {
-		return
-	}
-
-	log.Printf("An error occured. %v", err) // log error.
-
-	clientError, ok := err.(ClientError) // Check if it's a ClientError.
-	if !ok {
-		// If not ClientError, assume it's ServerError
-		w.WriteHeader(500)
-		return
-	}
-
-	body, err := clientError.ResponseBody() // Try to get response body of ClientError.
-	if err != nil {
-		log.Printf("An error accured: %v", err)
-		w.WriteHeader(500)
-		return
-	}
-
-	status, headers := clientError.ResponseHeaders() // Get http status code and headers.
-	for k, v := range headers {
-		w.Header().Set(k, v)
-	}
-	w.WriteHeader(status)
-	w.Write(body)
-}
-
 func main() {
 	// http.Handle accepts any type that implements http.Handler interface,
-	// so as long as you pass a type that has ServeHttp method, the http.Handle
+	// so as long as you pass a type that has ServeHTTP method, the http.Handle
 	// method will be happy.
-	http.Handle("/", rootHandler(testHandler))
+	http.Handle("/", &Handler{Fn: testHandler, IgnoreDisconnects: true})
 	log.Fatal(http.ListenAndServe(":8080", nil))
 }
 */
@@ -0,0 +1,400 @@
+package error_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	resterror "github.com/truescotian/resterror"
+)
+
+var discard = slog.New(slog.NewTextHandler(io.Discard, nil))
+
+func TestHandler(t *testing.T) {
+	var reported error
+	h := &resterror.Handler{
+		Fn: func(w http.ResponseWriter, r *http.Request) error {
+			return &resterror.Error{Op: "FindUser", Kind: resterror.ENOTFOUND, Status: 404, Message: "User not found."}
+		},
+		Logger:  discard,
+		OnError: func(r *http.Request, err error) { reported = err },
+	}
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/users/42", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("status=%d", rec.Code)
+	}
+	if ct := rec.Header().Get("Content-Type"); ct != "application/json; charset=utf-8" {
+		t.Fatalf("Content-Type=%q", ct)
+	}
+	var body map[string]interface{}
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatal(err)
+	}
+	if body["kind"] != resterror.ENOTFOUND || body["message"] != "User not found." {
+		t.Fatalf("body=%s", rec.Body)
+	}
+	if reported == nil {
+		t.Fatal("OnError wasn't called")
+	}
+}
+
+func TestHandler_UnknownError(t *testing.T) {
+	h := &resterror.Handler{
+		Fn: func(w http.ResponseWriter, r *http.Request) error {
+			return errors.New(`pq: syntax error at or near "INSERT"`)
+		},
+		Logger: discard,
+	}
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	if rec.Code != http.StatusInternalServerError {
+		t.Fatalf("status=%d", rec.Code)
+	}
+	if want := `{"kind":"internal","status":500,"message":"` + resterror.MsgInternal + `"}`; rec.Body.String() != want {
+		t.Fatalf("body=%s", rec.Body)
+	}
+}
+
+func TestHandler_IgnoreDisconnects(t *testing.T) {
+	for _, ignore := range []bool{true, false} {
+		called := false
+		h := &resterror.Handler{
+			Fn: func(w http.ResponseWriter, r *http.Request) error {
+				<-r.Context().Done()
+				return &resterror.Error{Op: "StreamUsers", Err: r.Context().Err()}
+			},
+			Logger:            discard,
+			OnError:           func(*http.Request, error) { called = true },
+			IgnoreDisconnects: ignore,
+		}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil).WithContext(ctx))
+		if called == ignore {
+			t.Errorf("IgnoreDisconnects=%v: OnError called=%v", ignore, called)
+		}
+		if ignore && rec.Body.Len() != 0 {
+			t.Errorf("IgnoreDisconnects=%v: body=%s", ignore, rec.Body)
+		}
+	}
+}
+
+func TestHandler_CollectedErrors(t *testing.T) {
+	h := &resterror.Handler{
+		Fn: func(w http.ResponseWriter, r *http.Request) error {
+			resterror.AddError(r.Context(), &resterror.Error{Kind: resterror.EINVALID, Status: 422, Message: "Avatar is too large, it was skipped."})
+			resterror.AddError(r.Context(), &resterror.Error{Kind: resterror.ENOTFOUND, Status: 404, Message: "Team not found, it was skipped."})
+			if n := len(resterror.CollectedErrors(r.Context())); n != 2 {
+				t.Errorf("collected %d errors", n)
+			}
+			return &resterror.Error{Kind: resterror.ECONFLICT, Status: 409, Message: "Username is already in use."}
+		},
+		Logger: discard,
+	}
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/users", nil))
+	if rec.Code != http.StatusConflict {
+		t.Fatalf("status=%d", rec.Code)
+	}
+	want := `{"kind":"conflict","status":409,"message":"Username is already in use.","causes":[` +
+		`{"kind":"invalid","status":422,"message":"Avatar is too large, it was skipped."},` +
+		`{"kind":"item_does_not_exist","status":404,"message":"Team not found, it was skipped."}]}`
+	if rec.Body.String() != want {
+		t.Fatalf("body=%s", rec.Body)
+	}
+}
+
+func TestNotFoundHandler(t *testing.T) {
+	rec := httptest.NewRecorder()
+	resterror.NotFoundHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/nope", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("status=%d", rec.Code)
+	}
+	if want := `{"kind":"route_not_found","status":404,"message":"` + resterror.MsgNotFound + `"}`; rec.Body.String() != want {
+		t.Fatalf("body=%s", rec.Body)
+	}
+}
+
+func TestNotFoundKinds(t *testing.T) {
+	route := &resterror.Error{Kind: resterror.ENOTFOUND_ROUTE}
+	resource := &resterror.Error{Kind: resterror.ENOTFOUND}
+	if resterror.SameKind(route, resource) {
+		t.Fatal("route and resource not found are the same kind")
+	}
+	for _, e := range []*resterror.Error{route, resource} {
+		if got := e.Public().Status; got != http.StatusNotFound {
+			t.Fatalf("status of %s=%d", e.Kind, got)
+		}
+	}
+	if got := resterror.KindForStatus(http.StatusNotFound); got != resterror.ENOTFOUND {
+		t.Fatalf("KindForStatus(404)=%q", got)
+	}
+}
+
+func TestMethodNotAllowedHandler(t *testing.T) {
+	rec := httptest.NewRecorder()
+	h := resterror.MethodNotAllowedHandler(http.MethodPost, "get")
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodDelete, "/users", nil))
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Fatalf("status=%d", rec.Code)
+	}
+	if allow := rec.Header().Get("Allow"); allow != "GET, POST" {
+		t.Fatalf("Allow=%q", allow)
+	}
+	if want := `{"kind":"method_not_allowed","status":405,"message":"Method not allowed"}`; rec.Body.String() != want {
+		t.Fatalf("body=%s", rec.Body)
+	}
+}
+
+// recorder is a slog.Handler recording the level of each record.
+type recorder struct {
+	levels []slog.Level
+}
+
+func (h *recorder) Enabled(context.Context, slog.Level) bool { return true }
+func (h *recorder) WithAttrs([]slog.Attr) slog.Handler       { return h }
+func (h *recorder) WithGroup(string) slog.Handler            { return h }
+func (h *recorder) Handle(_ context.Context, r slog.Record) error {
+	h.levels = append(h.levels, r.Level)
+	return nil
+}
+
+func TestHandler_LogLevel(t *testing.T) {
+	tests := []struct {
+		err         error
+		clientLevel slog.Level
+		want        slog.Level
+	}{
+		{&resterror.Error{Kind: resterror.ENOTFOUND, Status: 404}, 0, slog.LevelInfo},
+		{&resterror.Error{Kind: resterror.ENOTFOUND, Status: 404}, slog.LevelDebug, slog.LevelDebug},
+		{&resterror.Error{Kind: resterror.EINVALID}, slog.LevelWarn, slog.LevelWarn},
+		{&resterror.Error{Kind: resterror.EINTERNAL, Status: 500}, slog.LevelDebug, slog.LevelError},
+		{errors.New("boom"), 0, slog.LevelError},
+		{&resterror.Error{Kind: resterror.EINVALID, Severity: resterror.SeverityDebug}, slog.LevelWarn, slog.LevelDebug},
+		{&resterror.Error{Op: "db.Ping", Err: &resterror.Error{Kind: resterror.EINTERNAL, Severity: resterror.SeverityCritical}}, 0, resterror.LevelCritical},
+	}
+	for _, tt := range tests {
+		rec := &recorder{}
+		h := &resterror.Handler{
+			Fn:               func(http.ResponseWriter, *http.Request) error { return tt.err },
+			Logger:           slog.New(rec),
+			ClientErrorLevel: tt.clientLevel,
+		}
+		h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+		if len(rec.levels) != 1 || rec.levels[0] != tt.want {
+			t.Errorf("%v: logged at %v, want %v", tt.err, rec.levels, tt.want)
+		}
+	}
+}
+
+func TestHandler_NoBodyStatus(t *testing.T) {
+	tests := []struct {
+		name   string
+		err    error
+		status int
+		body   bool
+	}{
+		{"not modified", &resterror.Error{Kind: "not_modified", Status: http.StatusNotModified}, http.StatusNotModified, false},
+		{"no content", &resterror.Error{Kind: "no_content", Status: http.StatusNoContent}, http.StatusNoContent, false},
+		{"not found", &resterror.Error{Kind: resterror.ENOTFOUND, Status: http.StatusNotFound}, http.StatusNotFound, true},
+	}
+	// Both the error and the problem details writers, compressed or not.
+	handlers := []struct {
+		name     string
+		problems bool
+		compress int
+	}{
+		{"error", false, 0},
+		{"problem", true, 0},
+		{"compressed error", false, 1},
+		{"compressed problem", true, 1},
+	}
+	for _, hh := range handlers {
+		for _, tt := range tests {
+			t.Run(hh.name+"/"+tt.name, func(t *testing.T) {
+				h := &resterror.Handler{
+					Fn:                func(w http.ResponseWriter, r *http.Request) error { return tt.err },
+					Logger:            discard,
+					Problems:          hh.problems,
+					CompressThreshold: hh.compress,
+				}
+				req := httptest.NewRequest(http.MethodGet, "/users/42", nil)
+				req.Header.Set("Accept-Encoding", "gzip")
+				rec := httptest.NewRecorder()
+				h.ServeHTTP(rec, req)
+				if rec.Code != tt.status {
+					t.Fatalf("status=%d", rec.Code)
+				}
+				if got := rec.Body.Len() > 0; got != tt.body {
+					t.Fatalf("body=%q", rec.Body)
+				}
+				if got := rec.Header().Get("Content-Type") != ""; got != tt.body {
+					t.Fatalf("Content-Type=%q", rec.Header().Get("Content-Type"))
+				}
+			})
+		}
+	}
+}
+
+func TestHandler_IncidentIDs(t *testing.T) {
+	var logs bytes.Buffer
+	h := &resterror.Handler{
+		Fn: func(w http.ResponseWriter, r *http.Request) error {
+			if r.URL.Path == "/missing" {
+				return &resterror.Error{Kind: resterror.ENOTFOUND, Status: 404}
+			}
+			return &resterror.Error{Op: "FindUser", Err: errors.New("pq: connection refused")}
+		},
+		Logger:      slog.New(slog.NewTextHandler(&logs, nil)),
+		IncidentIDs: true,
+	}
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/users/42", nil))
+	var body map[string]interface{}
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatal(err)
+	}
+	id := rec.Header().Get(resterror.HeaderRequestID)
+	if id == "" || body["incident_id"] != id {
+		t.Fatalf("incident_id=%v, request ID=%q", body["incident_id"], id)
+	}
+	if body["message"] != resterror.MsgInternal {
+		t.Fatalf("body=%s", rec.Body)
+	}
+	if strings.Contains(rec.Body.String(), "pq:") {
+		t.Fatalf("body leaks the cause: %s", rec.Body)
+	}
+	if !strings.Contains(logs.String(), "incident_id="+id) || !strings.Contains(logs.String(), "pq: connection refused") {
+		t.Fatalf("logs=%s", &logs)
+	}
+
+	// Client errors carry no incident ID.
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/missing", nil))
+	if strings.Contains(rec.Body.String(), "incident_id") {
+		t.Fatalf("body=%s", rec.Body)
+	}
+}
+
+func TestHandle(t *testing.T) {
+	defer slog.SetDefault(slog.Default())
+	slog.SetDefault(discard)
+
+	tests := []struct {
+		name   string
+		fn     func(http.ResponseWriter, *http.Request) error
+		status int
+		body   string
+	}{
+		{
+			name:   "panic",
+			fn:     func(w http.ResponseWriter, r *http.Request) error { panic("nil map") },
+			status: http.StatusInternalServerError,
+			body:   `{"kind":"internal","status":500,"message":"` + resterror.MsgInternal + `"}`,
+		},
+		{
+			name: "error",
+			fn: func(w http.ResponseWriter, r *http.Request) error {
+				return &resterror.Error{Kind: resterror.ENOTFOUND, Message: "User not found."}
+			},
+			status: http.StatusNotFound,
+			body:   `{"kind":"item_does_not_exist","status":404,"message":"User not found."}`,
+		},
+		{
+			name: "success",
+			fn: func(w http.ResponseWriter, r *http.Request) error {
+				w.Write([]byte(`{"id":42}`))
+				return nil
+			},
+			status: http.StatusOK,
+			body:   `{"id":42}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			resterror.Handle(tt.fn).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/users/42", nil))
+			if rec.Code != tt.status || rec.Body.String() != tt.body {
+				t.Fatalf("status=%d body=%s", rec.Code, rec.Body)
+			}
+		})
+	}
+}
+
+func TestHandle_AbortHandler(t *testing.T) {
+	defer func() {
+		if v := recover(); v != http.ErrAbortHandler {
+			t.Fatalf("recovered %v, want http.ErrAbortHandler", v)
+		}
+	}()
+	h := resterror.Handle(func(w http.ResponseWriter, r *http.Request) error { panic(http.ErrAbortHandler) })
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+}
+
+func TestHandler_Strict(t *testing.T) {
+	var logs bytes.Buffer
+	h := &resterror.Handler{
+		Fn:     func(w http.ResponseWriter, r *http.Request) error { return errors.New("boom") },
+		Logger: slog.New(slog.NewTextHandler(&logs, nil)),
+		Strict: true,
+	}
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	if rec.Code != http.StatusInternalServerError {
+		t.Fatalf("status=%d", rec.Code)
+	}
+	if !strings.Contains(logs.String(), "unhandled error type *errors.errorString") {
+		t.Fatalf("logs=%s", &logs)
+	}
+}
+
+func TestHandler_Problems(t *testing.T) {
+	defer resterror.SetDefaultRegistry(resterror.DefaultRegistry())
+	resterror.SetDocsBaseURL("https://docs.example.com/errors")
+
+	h := &resterror.Handler{
+		Fn: func(w http.ResponseWriter, r *http.Request) error {
+			if r.URL.Path == "/users/42" {
+				return resterror.NotFound("FindUser", "User not found.")
+			}
+			return resterror.Internal("ListUsers", errors.New("pq: connection refused"))
+		},
+		Logger:      discard,
+		Problems:    true,
+		IncidentIDs: true,
+	}
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/users/42", nil))
+	if ct := rec.Header().Get("Content-Type"); ct != "application/problem+json" {
+		t.Fatalf("Content-Type=%q", ct)
+	}
+	want := `{"type":"https://docs.example.com/errors/item_does_not_exist","title":"Not Found","status":404,"detail":"User not found.","instance":"/users/42"}`
+	if got := rec.Body.String(); rec.Code != 404 || got != want {
+		t.Fatalf("status=%d body=%s, want %s", rec.Code, got, want)
+	}
+
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/users", nil))
+	var p resterror.Problem
+	if err := json.Unmarshal(rec.Body.Bytes(), &p); err != nil {
+		t.Fatal(err)
+	}
+	if id := rec.Header().Get(resterror.HeaderRequestID); p.Status != 500 || p.IncidentID != id || p.Detail != resterror.MsgInternal {
+		t.Fatalf("problem=%+v, request ID=%q", p, id)
+	}
+}
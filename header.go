@@ -0,0 +1,65 @@
+package error
+
+import (
+	"net/url"
+	"strings"
+)
+
+// sanitizeHeaders adds the valid headers of src to dst, stripping CR and LF
+// from their values so that attacker-influenced values, such as a Message,
+// can't inject headers. Headers with an invalid name or value are skipped
+// and logged to the logger of r.
+func (r *Registry) sanitizeHeaders(dst, src map[string]string) {
+	for name, value := range src {
+		v, ok := sanitizeHeaderValue(value)
+		if !validHeaderName(name) || !ok {
+			r.log().Warn("skipping invalid response header", "header", name)
+			continue
+		}
+		dst[name] = v
+	}
+}
+
+// sanitizeHeaderValue strips CR and LF from v, and reports whether the
+// result is a valid header value.
+func sanitizeHeaderValue(v string) (string, bool) {
+	v = strings.NewReplacer("\r", "", "\n", "").Replace(v)
+	for i := 0; i < len(v); i++ {
+		if b := v[i]; (b < ' ' && b != '\t') || b == 0x7f {
+			return "", false
+		}
+	}
+	return v, true
+}
+
+// validHeaderName reports whether name is a valid header field name, that is
+// a non-empty RFC 7230 token.
+func validHeaderName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for i := 0; i < len(name); i++ {
+		b := name[i]
+		switch {
+		case 'a' <= b && b <= 'z', 'A' <= b && b <= 'Z', '0' <= b && b <= '9':
+		case strings.IndexByte("!#$%&'*+-.^_`|~", b) >= 0:
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// validLocation reports whether loc is a valid URL path for the Location
+// header, e.g. /users/42. Absolute and protocol-relative URLs (//host/path)
+// are rejected so errors can't redirect clients to another host.
+func validLocation(loc string) bool {
+	if !strings.HasPrefix(loc, "/") || strings.HasPrefix(loc, "//") || strings.HasPrefix(loc, "/\\") {
+		return false
+	}
+	if v, ok := sanitizeHeaderValue(loc); !ok || v != loc {
+		return false
+	}
+	u, err := url.Parse(loc)
+	return err == nil && u.Scheme == "" && u.Host == ""
+}
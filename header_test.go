@@ -0,0 +1,106 @@
+package error_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	resterror "github.com/truescotian/resterror"
+)
+
+func TestResponseHeaders_Injection(t *testing.T) {
+	msg := "Unknown locale fr\r\nSet-Cookie: session=stolen"
+	e := &resterror.Error{
+		Kind:    resterror.EINVALID,
+		Status:  422,
+		Message: msg,
+		Headers: map[string]string{
+			"X-Error-Reason": msg,
+			"Bad Name":       "value",
+			"X-Null":         "a\x00b",
+		},
+	}
+
+	rec := httptest.NewRecorder()
+	resterror.WriteError(rec, e)
+	h := rec.Result().Header
+	if got := h.Get("X-Error-Reason"); got != "Unknown locale frSet-Cookie: session=stolen" {
+		t.Fatalf("X-Error-Reason=%q", got)
+	}
+	if got := h.Get("Set-Cookie"); got != "" {
+		t.Fatalf("injected Set-Cookie=%q", got)
+	}
+	if _, ok := h["Bad Name"]; ok {
+		t.Fatal("invalid header name was emitted")
+	}
+	if _, ok := h["X-Null"]; ok {
+		t.Fatal("invalid header value was emitted")
+	}
+	if rec.Code != http.StatusUnprocessableEntity || h.Get("Content-Type") != "application/json; charset=utf-8" {
+		t.Fatalf("status=%d headers=%v", rec.Code, h)
+	}
+}
+
+func TestResponseHeaders_Location(t *testing.T) {
+	tests := []struct {
+		location string
+		want     string
+	}{
+		{"/users/42", "/users/42"},
+		{"/users/jane%20doe?include=profile", "/users/jane%20doe?include=profile"},
+		{"", ""},
+		{"users/42", ""},
+		{"https://evil.example.com/users/42", ""},
+		{"//evil.example.com/users/42", ""},
+		{`/\evil.example.com`, ""},
+		{"/users/42\r\nSet-Cookie: a=b", ""},
+		{"/users/%zz", ""},
+	}
+	for _, tt := range tests {
+		e := resterror.NewExistsError("CreateUser", "Username is taken.").WithLocation(tt.location)
+		_, headers := (&resterror.Error{Op: "POST /users", Err: e}).ResponseHeaders()
+		if got := headers["Location"]; got != tt.want {
+			t.Fatalf("Location for %q=%q, want %q", tt.location, got, tt.want)
+		}
+	}
+}
+
+func TestContentType(t *testing.T) {
+	tests := []struct {
+		name        string
+		err         *resterror.Error
+		contentType string
+		body        string
+	}{
+		{
+			name:        "default",
+			err:         &resterror.Error{Kind: resterror.EINTERNAL, Message: "Database unreachable."},
+			contentType: "application/json; charset=utf-8",
+			body:        `{"kind":"internal","status":500,"message":"` + resterror.MsgInternal + `"}`,
+		},
+		{
+			name:        "text",
+			err:         &resterror.Error{Kind: resterror.EINTERNAL, Message: "Database unreachable.", AllowMessage: true, ContentType: "text/plain; charset=utf-8"},
+			contentType: "text/plain; charset=utf-8",
+			body:        "Database unreachable.",
+		},
+		{
+			name:        "unknown type",
+			err:         &resterror.Error{Op: "Health", Err: &resterror.Error{Kind: resterror.ENOTFOUND, ContentType: "application/x-health"}},
+			contentType: "application/x-health",
+			body:        resterror.MsgNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			resterror.WriteError(rec, tt.err)
+			if got := rec.Header().Get("Content-Type"); got != tt.contentType {
+				t.Fatalf("Content-Type=%q", got)
+			}
+			if rec.Body.String() != tt.body {
+				t.Fatalf("body=%q", rec.Body)
+			}
+		})
+	}
+}
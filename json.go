@@ -0,0 +1,114 @@
+package error
+
+import (
+	"encoding/json"
+	"strconv"
+	"unicode/utf8"
+)
+
+// marshalJSON returns the JSON encoding of e.
+//
+// Nearly every error sent to a client is a flat Kind, Status and Message, so
+// those are encoded by hand, without reflection. Anything else falls back to
+// json.Marshal. Both produce the exact same bytes.
+//
+// e is taken by value so that it stays on the stack in the fast path.
+func marshalJSON(e Error, indent bool) ([]byte, error) {
+	if indent {
+		indented := e
+		return json.MarshalIndent(&indented, "", "  ")
+	}
+	if e.isFlat() {
+		return appendFlatJSON(make([]byte, 0, 40+len(e.Kind)+len(e.Message)), &e), nil
+	}
+	nested := e
+	return json.Marshal(&nested)
+}
+
+// isFlat reports whether Kind, Status and Message are the only serialized
+// fields of e which are set.
+func (e *Error) isFlat() bool {
+	return e.Code == 0 && e.Detail == "" && e.Hint == "" && e.Remediation == "" && e.DocsURL == "" && len(e.Details) == 0 && len(e.ErrorDetails) == 0 && e.IncidentID == "" && len(e.Causes) == 0 && len(e.Items) == 0
+}
+
+// appendFlatJSON appends the JSON encoding of a flat e to dst.
+func appendFlatJSON(dst []byte, e *Error) []byte {
+	dst = append(dst, '{')
+	sep := false
+	if e.Kind != "" {
+		dst = append(dst, `"kind":`...)
+		dst = appendJSONString(dst, e.Kind)
+		sep = true
+	}
+	if e.Status != 0 {
+		if sep {
+			dst = append(dst, ',')
+		}
+		dst = append(dst, `"status":`...)
+		dst = strconv.AppendInt(dst, int64(e.Status), 10)
+		sep = true
+	}
+	if e.Message != "" {
+		if sep {
+			dst = append(dst, ',')
+		}
+		dst = append(dst, `"message":`...)
+		dst = appendJSONString(dst, e.Message)
+	}
+	return append(dst, '}')
+}
+
+const hex = "0123456789abcdef"
+
+// appendJSONString appends s to dst as a JSON string, escaped the same way as
+// json.Marshal does (including HTML escaping).
+func appendJSONString(dst []byte, s string) []byte {
+	dst = append(dst, '"')
+	start := 0
+	for i := 0; i < len(s); {
+		if b := s[i]; b < utf8.RuneSelf {
+			if b >= 0x20 && b != '"' && b != '\\' && b != '<' && b != '>' && b != '&' {
+				i++
+				continue
+			}
+			dst = append(dst, s[start:i]...)
+			switch b {
+			case '"', '\\':
+				dst = append(dst, '\\', b)
+			case '\b':
+				dst = append(dst, '\\', 'b')
+			case '\f':
+				dst = append(dst, '\\', 'f')
+			case '\n':
+				dst = append(dst, '\\', 'n')
+			case '\r':
+				dst = append(dst, '\\', 'r')
+			case '\t':
+				dst = append(dst, '\\', 't')
+			default:
+				dst = append(dst, '\\', 'u', '0', '0', hex[b>>4], hex[b&0xF])
+			}
+			i++
+			start = i
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(s[i:])
+		if r == utf8.RuneError && size == 1 {
+			dst = append(dst, s[start:i]...)
+			dst = append(dst, "\ufffd"...)
+			i += size
+			start = i
+			continue
+		}
+		if r == '\u2028' || r == '\u2029' {
+			dst = append(dst, s[start:i]...)
+			dst = append(dst, '\\', 'u', '2', '0', '2', hex[r&0xF])
+			i += size
+			start = i
+			continue
+		}
+		i += size
+	}
+	dst = append(dst, s[start:]...)
+	return append(dst, '"')
+}
@@ -0,0 +1,114 @@
+package error_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+
+	resterror "github.com/truescotian/resterror"
+)
+
+func TestResponseBody_MatchesMarshal(t *testing.T) {
+	messages := []string{
+		"",
+		"User not found.",
+		`Wrong "password" \ or username`,
+		"line\nbreak\ttab\rreturn\bback\fform\x00nul\x1f",
+		"<script>alert('&')</script>",
+		"héllo wörld ✓ 日本語",
+		"bad utf-8 \xff\xfe",
+		"separators \u2028 \u2029",
+	}
+	for _, msg := range messages {
+		e := &resterror.Error{Kind: resterror.EINVALID, Status: 422, Message: msg}
+		got, err := e.ResponseBody()
+		if err != nil {
+			t.Fatal(err)
+		}
+		want, err := json.Marshal(e.Public())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(got) != string(want) {
+			t.Errorf("message %q:\n got %s\nwant %s", msg, got, want)
+		}
+	}
+
+	for _, e := range []*resterror.Error{{}, {Kind: resterror.ENOTFOUND}, {Status: 404}, {Message: "x"}} {
+		got, _ := e.Public().ResponseBody()
+		want, _ := json.Marshal(e.Public())
+		if string(got) != string(want) {
+			t.Errorf("%#v:\n got %s\nwant %s", e, got, want)
+		}
+	}
+}
+
+func BenchmarkResponseBody(b *testing.B) {
+	e := &resterror.Error{Kind: resterror.ENOTFOUND, Status: 404, Message: "User not found."}
+
+	b.Run("flat", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			e.ResponseBody()
+		}
+	})
+	b.Run("json.Marshal", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			json.Marshal(e.Public())
+		}
+	})
+}
+
+func TestSetIndent(t *testing.T) {
+	e := &resterror.Error{Kind: resterror.ENOTFOUND, Status: 404, Message: "User not found."}
+
+	compact, _ := e.ResponseBody()
+	if want := `{"kind":"item_does_not_exist","status":404,"message":"User not found."}`; string(compact) != want {
+		t.Fatalf("compact body:\n%s", compact)
+	}
+
+	resterror.SetIndent(true)
+	defer resterror.SetIndent(false)
+	indented, _ := e.ResponseBody()
+	want := "{\n  \"kind\": \"item_does_not_exist\",\n  \"status\": 404,\n  \"message\": \"User not found.\"\n}"
+	if string(indented) != want {
+		t.Fatalf("indented body:\n%s", indented)
+	}
+}
+
+func TestResponseBody_UnmarshalableDetails(t *testing.T) {
+	e := &resterror.Error{
+		Kind:    resterror.EINVALID,
+		Status:  422,
+		Message: "Invalid subscription.",
+		Details: map[string]interface{}{"events": make(chan int)},
+	}
+	body, err := e.ResponseBody()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `{"kind":"invalid","status":422,"message":"Invalid subscription."}`; string(body) != want {
+		t.Fatalf("body=%s", body)
+	}
+}
+
+func TestResponseBody_LogsToRegistryLogger(t *testing.T) {
+	var logs bytes.Buffer
+	r := resterror.NewRegistry().WithLogger(slog.New(slog.NewTextHandler(&logs, nil)))
+
+	e := &resterror.Error{Kind: resterror.EINVALID, Details: map[string]interface{}{"events": make(chan int)}}
+	if _, err := r.ResponseBody(e); err != nil {
+		t.Fatal(err)
+	}
+	e = &resterror.Error{Kind: resterror.EEXIST, Location: "javascript:alert(1)", Headers: map[string]string{"Bad Name": "x"}}
+	r.ResponseHeaders(e)
+
+	for _, msg := range []string{"unable to marshal response body", "skipping invalid Location header", "skipping invalid response header"} {
+		if !strings.Contains(logs.String(), msg) {
+			t.Errorf("logs=%s, want %q", logs.String(), msg)
+		}
+	}
+}
@@ -0,0 +1,198 @@
+package error
+
+import (
+	"log/slog"
+	"strings"
+	"time"
+)
+
+// OpTrace returns the logical stack trace of err, that is its Ops joined by
+// ": ".
+func OpTrace(err error) string {
+	return strings.Join(Ops(err), ": ")
+}
+
+// Ops returns the logical stack trace of err, that is the Op and Ops of
+// every *Error in the chain of err, outermost first. Consecutive repeats of
+// an op, as in recursive calls, appear once.
+func Ops(err error) []string {
+	return DefaultRegistry().ops(err)
+}
+
+// ops is like Ops but resolves joined errors with the join policy of r.
+func (r *Registry) ops(err error) []string {
+	var ops []string
+	r.walk(err, func(e *Error) bool {
+		for _, op := range e.layerOps() {
+			if len(ops) == 0 || ops[len(ops)-1] != op {
+				ops = append(ops, op)
+			}
+		}
+		return true
+	})
+	return ops
+}
+
+// Cause returns the root cause of err, that is the innermost error of the
+// chain of Error.Err. Returns err itself if it doesn't wrap anything.
+func Cause(err error) error {
+	for depth := 0; depth < maxDepth; depth++ {
+		e, ok := err.(*Error)
+		if !ok || e.Err == nil {
+			return err
+		}
+		err = e.Err
+	}
+	return err
+}
+
+// LogValue implements slog.LogValuer.
+//
+// The wrapped Err is deliberately omitted since it may hold details which
+// shouldn't end up in shared logs, use LogAttrs for operator logs.
+func (e *Error) LogValue() slog.Value {
+	return slog.GroupValue(
+		slog.String("op_trace", OpTrace(e)),
+		slog.String("kind", ErrorKind(e)),
+		slog.Int("status", ErrorStatus(e)),
+		slog.String("message", DefaultRegistry().truncate(ErrorMessage(e))),
+	)
+}
+
+// LogAttrs returns the attributes describing err for server-side logging.
+//
+// Unlike LogValue, the raw root cause, all the Details, including those
+// marked internal, and the Fields of the chain are included so operators can
+// debug the error. These attributes must never be sent to a client.
+func LogAttrs(err error) []slog.Attr {
+	return DefaultRegistry().LogAttrs(err)
+}
+
+// LogAttrs is like the package-level LogAttrs but resolves err with r.
+func (r *Registry) LogAttrs(err error) []slog.Attr {
+	if err == nil {
+		return nil
+	}
+	attrs := []slog.Attr{
+		slog.String("op_trace", strings.Join(r.ops(err), ": ")),
+		slog.String("kind", r.errorKind(err)),
+		slog.Int("status", r.ErrorStatus(err)),
+		slog.String("cause", r.truncate(Cause(err).Error())),
+	}
+	if e, ok := r.asError(err); ok {
+		attrs = append(attrs, slog.String("fingerprint", r.fingerprint(e)))
+	}
+	if e, ok := r.lookup(err, func(e *Error) bool { return len(e.Details) != 0 }); ok {
+		attrs = append(attrs, slog.Any("details", e.Details))
+	}
+	if id := r.errorRequestID(err); id != "" {
+		attrs = append(attrs, slog.String("request_id", id))
+	}
+	if id := r.errorTraceID(err); id != "" {
+		attrs = append(attrs, slog.String("trace_id", id))
+	}
+	if t := r.errorTime(err); !t.IsZero() {
+		attrs = append(attrs, slog.Time("error_time", t))
+	}
+	if tags := r.errorTags(err); len(tags) != 0 {
+		attrs = append(attrs, slog.Any("tags", tags))
+	}
+	if fields := r.errorFields(err); len(fields) != 0 {
+		attrs = append(attrs, slog.Any("fields", fields))
+	}
+	return attrs
+}
+
+// errorFields merges the Fields of every *Error in the chain of err. When
+// several errors set the same key, the outermost one wins.
+func (r *Registry) errorFields(err error) map[string]interface{} {
+	var layers []*Error
+	r.walk(err, func(e *Error) bool {
+		if len(e.Fields) != 0 {
+			layers = append(layers, e)
+		}
+		return true
+	})
+
+	var fields map[string]interface{}
+	for i := len(layers) - 1; i >= 0; i-- {
+		for k, v := range layers[i].Fields {
+			if fields == nil {
+				fields = make(map[string]interface{})
+			}
+			fields[k] = v
+		}
+	}
+	return fields
+}
+
+// ErrorTime returns when err originally occurred, that is the Time of the
+// innermost *Error of its chain which has one. Returns the zero time if none
+// has.
+func ErrorTime(err error) time.Time {
+	return DefaultRegistry().errorTime(err)
+}
+
+// errorTime is like ErrorTime but resolves joined errors with the join
+// policy of r.
+func (r *Registry) errorTime(err error) time.Time {
+	var t time.Time
+	r.walk(err, func(e *Error) bool {
+		if !e.Time.IsZero() {
+			t = e.Time
+		}
+		return true
+	})
+	return t
+}
+
+// ErrorRequestID returns the first RequestID found in the chain of err.
+func ErrorRequestID(err error) string {
+	return DefaultRegistry().errorRequestID(err)
+}
+
+// errorRequestID is like ErrorRequestID but resolves joined errors with the
+// join policy of r.
+func (r *Registry) errorRequestID(err error) string {
+	if e, ok := r.lookup(err, func(e *Error) bool { return e.RequestID != "" }); ok {
+		return e.RequestID
+	}
+	return ""
+}
+
+// ErrorTraceID returns the first TraceID found in the chain of err.
+func ErrorTraceID(err error) string {
+	return DefaultRegistry().errorTraceID(err)
+}
+
+// errorTraceID is like ErrorTraceID but resolves joined errors with the join
+// policy of r.
+func (r *Registry) errorTraceID(err error) string {
+	if e, ok := r.lookup(err, func(e *Error) bool { return e.TraceID != "" }); ok {
+		return e.TraceID
+	}
+	return ""
+}
+
+// ErrorTags returns the Tags of every *Error in the chain of err, outermost
+// first, without duplicates.
+func ErrorTags(err error) []string {
+	return DefaultRegistry().errorTags(err)
+}
+
+// errorTags is like ErrorTags but resolves joined errors with the join
+// policy of r.
+func (r *Registry) errorTags(err error) []string {
+	var tags []string
+	seen := make(map[string]bool)
+	r.walk(err, func(e *Error) bool {
+		for _, tag := range e.Tags {
+			if !seen[tag] {
+				seen[tag] = true
+				tags = append(tags, tag)
+			}
+		}
+		return true
+	})
+	return tags
+}
@@ -0,0 +1,174 @@
+package error_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	resterror "github.com/truescotian/resterror"
+)
+
+func TestLogAttrs(t *testing.T) {
+	err := &resterror.Error{
+		Op: "UserService.CreateUser",
+		Err: &resterror.Error{
+			Op:  "attachRole",
+			Err: errors.New(`syntax error at or near "INSERT"`),
+		},
+	}
+
+	attrs := map[string]slog.Value{}
+	for _, a := range resterror.LogAttrs(err) {
+		attrs[a.Key] = a.Value
+	}
+	if got := attrs["op_trace"].String(); got != "UserService.CreateUser: attachRole" {
+		t.Fatalf("op_trace=%q", got)
+	}
+	if got := attrs["kind"].String(); got != resterror.EINTERNAL {
+		t.Fatalf("kind=%q", got)
+	}
+	if got := attrs["cause"].String(); got != `syntax error at or near "INSERT"` {
+		t.Fatalf("cause=%q", got)
+	}
+
+	var buf bytes.Buffer
+	slog.New(slog.NewJSONHandler(&buf, nil)).Info("request failed", "error", err)
+	if strings.Contains(buf.String(), "syntax error") {
+		t.Fatalf("LogValue leaks the cause: %s", buf.String())
+	}
+	if !strings.Contains(buf.String(), "attachRole") {
+		t.Fatalf("LogValue is missing the op trace: %s", buf.String())
+	}
+}
+
+func TestOpTrace_Ops(t *testing.T) {
+	tests := []struct {
+		err  *resterror.Error
+		want string
+	}{
+		{&resterror.Error{Op: "UserService.CreateUser"}, "UserService.CreateUser"},
+		{&resterror.Error{Ops: []string{"POST /users", "UserService.CreateUser"}}, "POST /users: UserService.CreateUser"},
+		{&resterror.Error{Op: "POST /users", Ops: []string{"UserService.CreateUser"}}, "POST /users: UserService.CreateUser"},
+		{
+			&resterror.Error{Ops: []string{"POST /users", "UserService.CreateUser"}, Err: &resterror.Error{Op: "insertUser"}},
+			"POST /users: UserService.CreateUser: insertUser",
+		},
+	}
+	for _, tt := range tests {
+		if got := resterror.OpTrace(tt.err); got != tt.want {
+			t.Errorf("OpTrace()=%q, want %q", got, tt.want)
+		}
+		if got := tt.err.Error(); got != tt.want+": " {
+			t.Errorf("Error()=%q, want %q", got, tt.want+": ")
+		}
+	}
+}
+
+func TestOps(t *testing.T) {
+	err := fmt.Errorf("handler: %w", &resterror.Error{
+		Ops: []string{"POST /users", "UserService.CreateUser"},
+		Err: &resterror.Error{Op: "insertUser", Err: &resterror.Error{Op: "insertUser", Err: errors.New("boom")}},
+	})
+	want := []string{"POST /users", "UserService.CreateUser", "insertUser"}
+	if got := resterror.Ops(err); !reflect.DeepEqual(got, want) {
+		t.Fatalf("Ops()=%q, want %q", got, want)
+	}
+	if got := resterror.Ops(errors.New("boom")); got != nil {
+		t.Fatalf("Ops(plain)=%q", got)
+	}
+}
+
+func TestLogAttrs_Fields(t *testing.T) {
+	inner := (&resterror.Error{Op: "db.Insert", Kind: resterror.EEXIST}).
+		WithField("user_id", 42).
+		WithField("table", "users")
+	err := (&resterror.Error{Op: "CreateUser", Err: inner}).WithField("user_id", 7).WithField("plan", "pro")
+
+	var buf bytes.Buffer
+	slog.New(slog.NewJSONHandler(&buf, nil)).LogAttrs(context.Background(), slog.LevelInfo, "request failed", resterror.LogAttrs(err)...)
+	if want := `"fields":{"plan":"pro","table":"users","user_id":7}`; !strings.Contains(buf.String(), want) {
+		t.Fatalf("logs=%s, want %s", &buf, want)
+	}
+
+	body, _ := err.ResponseBody()
+	if strings.Contains(string(body), "user_id") {
+		t.Fatalf("fields are serialized: %s", body)
+	}
+}
+
+func TestErrorTime(t *testing.T) {
+	defer resterror.SetDefaultRegistry(resterror.DefaultRegistry())
+	occurred := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	resterror.SetClock(func() time.Time { return occurred })
+	inner := resterror.NewError("db.Insert", 500, "", resterror.EINTERNAL, nil)
+
+	// Wrapping later, e.g. when a queued job is retried, keeps the original
+	// time.
+	resterror.SetClock(func() time.Time { return occurred.Add(time.Hour) })
+	err := resterror.Wrap(inner, "ProcessJob")
+	if got := resterror.ErrorTime(err); !got.Equal(occurred) {
+		t.Fatalf("ErrorTime()=%v, want %v", got, occurred)
+	}
+	if got := resterror.ErrorTime(errors.New("boom")); !got.IsZero() {
+		t.Fatalf("ErrorTime(plain)=%v", got)
+	}
+
+	var buf bytes.Buffer
+	slog.New(slog.NewJSONHandler(&buf, nil)).LogAttrs(context.Background(), slog.LevelInfo, "job failed", resterror.LogAttrs(err)...)
+	if want := `"error_time":"2024-03-01T12:00:00Z"`; !strings.Contains(buf.String(), want) {
+		t.Fatalf("logs=%s, want %s", &buf, want)
+	}
+}
+
+func TestErrorRequestID(t *testing.T) {
+	ctx := resterror.ContextWithRequestID(context.Background(), "req-1")
+	inner := resterror.NewErrorCtx(ctx, 500, "", resterror.EINTERNAL, nil)
+	inner.TraceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+	err := resterror.Wrap(inner, "ProcessJob")
+
+	if got := resterror.ErrorRequestID(err); got != "req-1" {
+		t.Fatalf("ErrorRequestID()=%q", got)
+	}
+	if got := resterror.ErrorTraceID(err); got != "4bf92f3577b34da6a3ce929d0e0e4736" {
+		t.Fatalf("ErrorTraceID()=%q", got)
+	}
+	if got := resterror.ErrorRequestID(errors.New("boom")); got != "" {
+		t.Fatalf("ErrorRequestID(plain)=%q", got)
+	}
+
+	attrs := map[string]slog.Value{}
+	for _, a := range resterror.LogAttrs(err) {
+		attrs[a.Key] = a.Value
+	}
+	if got := attrs["request_id"].String(); got != "req-1" {
+		t.Fatalf("request_id=%q", got)
+	}
+	if got := attrs["trace_id"].String(); got != "4bf92f3577b34da6a3ce929d0e0e4736" {
+		t.Fatalf("trace_id=%q", got)
+	}
+}
+
+func TestErrorTags(t *testing.T) {
+	inner := (&resterror.Error{Op: "stripe.Charge", Kind: resterror.EINTERNAL}).WithTags("billing", "tenant:acme")
+	err := fmt.Errorf("checkout: %w", (&resterror.Error{Op: "Checkout", Err: inner}).WithTags("beta-feature", "billing"))
+
+	want := []string{"beta-feature", "billing", "tenant:acme"}
+	if got := resterror.ErrorTags(err); !reflect.DeepEqual(got, want) {
+		t.Fatalf("ErrorTags()=%q, want %q", got, want)
+	}
+	if got := resterror.ErrorTags(errors.New("boom")); got != nil {
+		t.Fatalf("ErrorTags(plain)=%q", got)
+	}
+
+	var buf bytes.Buffer
+	slog.New(slog.NewJSONHandler(&buf, nil)).LogAttrs(context.Background(), slog.LevelError, "checkout failed", resterror.LogAttrs(err)...)
+	if want := `"tags":["beta-feature","billing","tenant:acme"]`; !strings.Contains(buf.String(), want) {
+		t.Fatalf("logs=%s, want %s", &buf, want)
+	}
+}
@@ -0,0 +1,50 @@
+package error
+
+// Match reports whether got is an *Error whose fields match the non-zero
+// Op, Kind, Status, Code and Message of template, for assertions in tests
+// which don't depend on the whole Error() string:
+//
+//	want := &resterror.Error{Kind: resterror.ENOTFOUND, Err: &resterror.Error{Op: "queryUser"}}
+//	if !resterror.Match(want, err) { ... }
+//
+// If template has an Err, got must have one too: an *Error Err is matched
+// recursively, any other error must have the same Error() string.
+//
+// Like upspin, Match doesn't resolve fields through the chain, each layer of
+// template is compared with the matching layer of got.
+//
+// Source: https://upspin.googlesource.com/upspin/+/033a63d02f07/errors/errors.go
+func Match(template, got error) bool {
+	t, ok := template.(*Error)
+	if !ok {
+		return false
+	}
+	e, ok := got.(*Error)
+	if !ok {
+		return false
+	}
+	if t.Op != "" && t.Op != e.Op {
+		return false
+	}
+	if t.Kind != "" && t.Kind != e.Kind {
+		return false
+	}
+	if t.Status != 0 && t.Status != e.Status {
+		return false
+	}
+	if t.Code != 0 && t.Code != e.Code {
+		return false
+	}
+	if t.Message != "" && t.Message != e.Message {
+		return false
+	}
+	if t.Err != nil {
+		if _, ok := t.Err.(*Error); ok {
+			return Match(t.Err, e.Err)
+		}
+		if e.Err == nil || e.Err.Error() != t.Err.Error() {
+			return false
+		}
+	}
+	return true
+}
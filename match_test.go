@@ -0,0 +1,45 @@
+package error_test
+
+import (
+	"errors"
+	"testing"
+
+	resterror "github.com/truescotian/resterror"
+)
+
+func TestMatch(t *testing.T) {
+	got := &resterror.Error{
+		Op:   "UserService.FindUserByID",
+		Kind: resterror.ENOTFOUND,
+		Err: &resterror.Error{
+			Op:      "queryUser",
+			Message: "User not found.",
+			Err:     errors.New("sql: no rows in result set"),
+		},
+	}
+
+	tests := []struct {
+		name     string
+		template error
+		want     bool
+	}{
+		{"empty", &resterror.Error{}, true},
+		{"kind", &resterror.Error{Kind: resterror.ENOTFOUND}, true},
+		{"other kind", &resterror.Error{Kind: resterror.EINVALID}, false},
+		{"nested op", &resterror.Error{Err: &resterror.Error{Op: "queryUser"}}, true},
+		{"nested message", &resterror.Error{Err: &resterror.Error{Message: "User missing."}}, false},
+		{"cause", &resterror.Error{Err: &resterror.Error{Err: errors.New("sql: no rows in result set")}}, true},
+		{"other cause", &resterror.Error{Err: &resterror.Error{Err: errors.New("sql: connection refused")}}, false},
+		{"deeper than got", &resterror.Error{Err: &resterror.Error{Err: &resterror.Error{}}}, false},
+		{"status", &resterror.Error{Status: 404}, false},
+		{"plain template", errors.New("boom"), false},
+	}
+	for _, tt := range tests {
+		if match := resterror.Match(tt.template, got); match != tt.want {
+			t.Errorf("%s: Match()=%v, want %v", tt.name, match, tt.want)
+		}
+	}
+	if resterror.Match(&resterror.Error{}, errors.New("boom")) {
+		t.Fatal("Match() of a plain error")
+	}
+}
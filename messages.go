@@ -1,8 +1,61 @@
 package error
 
+import "strings"
+
 // Human readable messages.
 //
 // TODO(truescotian): This needs to be i18n.
 const (
-	MsgDecodeBody = "Body was unable to be decoded."
+	MsgDecodeBody       = "Body was unable to be decoded."
+	MsgBodyTooLarge     = "Body is too large."
+	MsgUnsupportedType  = "Expected application/json"
+	MsgInternal         = "An internal error has occurred. Please contact technical support."
+	MsgValidation       = "One or more fields are invalid."
+	MsgNotFound         = "The requested resource was not found."
+	MsgMethodNotAllowed = "Method not allowed"
+	MsgQuota            = "The quota of your plan is exceeded."
 )
+
+// RegisterMessage registers the text/template text rendering the message of
+// the errors whose MessageKey is key, with their Params as data:
+//
+//	resterror.RegisterMessage("user.not_found", "User {{.id}} was not found.")
+//
+//	return &resterror.Error{
+//		Kind:       resterror.ENOTFOUND,
+//		MessageKey: "user.not_found",
+//		Params:     map[string]interface{}{"id": id},
+//	}
+//
+// Messages are rendered when the error is resolved, e.g. in its response,
+// so the text can be changed, for instance per tenant with a Registry of its
+// own, without touching the call sites. It panics if text isn't a valid
+// template.
+func RegisterMessage(key, text string) {
+	updateDefaultRegistry(func(r *Registry) *Registry { return r.WithMessageTemplate(key, text) })
+}
+
+// hasMessage reports whether e defines a message, either as is or through
+// a MessageKey.
+func hasMessage(e *Error) bool {
+	return e.Message != "" || e.MessageKey != ""
+}
+
+// message returns the message of e, rendered from the template of its
+// MessageKey in r if there is one. If the key isn't registered, or the
+// template fails, e.g. because of a missing param, Message is returned and,
+// failing that, the message of the kind of e.
+func (r *Registry) message(e *Error) string {
+	if tmpl, ok := r.messages[e.MessageKey]; ok {
+		var buf strings.Builder
+		err := tmpl.Execute(&buf, e.Params)
+		if err == nil {
+			return buf.String()
+		}
+		r.log().Warn("unable to render error message", "message_key", e.MessageKey, "error", err)
+	}
+	if e.Message != "" {
+		return e.Message
+	}
+	return r.MessageForKind(r.errorKind(e))
+}
@@ -0,0 +1,61 @@
+package error_test
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"testing"
+
+	resterror "github.com/truescotian/resterror"
+)
+
+func TestRegisterMessage(t *testing.T) {
+	defer resterror.SetDefaultRegistry(resterror.DefaultRegistry())
+	resterror.RegisterMessage("user.not_found", "User {{.id}} was not found.")
+
+	err := &resterror.Error{
+		Op: "UserService.FindUserByID",
+		Err: &resterror.Error{
+			Kind:       resterror.ENOTFOUND,
+			MessageKey: "user.not_found",
+			Params:     map[string]interface{}{"id": 42},
+		},
+	}
+	if got, want := resterror.ErrorMessage(err), "User 42 was not found."; got != want {
+		t.Fatalf("ErrorMessage()=%q, want %q", got, want)
+	}
+	body, _ := err.ResponseBody()
+	if want := `{"kind":"item_does_not_exist","status":404,"message":"User 42 was not found."}`; string(body) != want {
+		t.Fatalf("body=%s, want %s", body, want)
+	}
+
+	// A per-tenant registry overrides the text without touching the error.
+	tenant := resterror.DefaultRegistry().WithMessageTemplate("user.not_found", "No member #{{.id}}.")
+	if got, want := tenant.ErrorMessage(err), "No member #42."; got != want {
+		t.Fatalf("tenant ErrorMessage()=%q, want %q", got, want)
+	}
+}
+
+func TestRegisterMessage_Fallback(t *testing.T) {
+	defer resterror.SetDefaultRegistry(resterror.DefaultRegistry())
+	resterror.RegisterMessage("user.not_found", "User {{.id}} was not found.")
+	var logs bytes.Buffer
+	resterror.SetLogger(slog.New(slog.NewTextHandler(&logs, nil)))
+
+	tests := []struct {
+		name string
+		err  *resterror.Error
+		want string
+	}{
+		{"missing param", &resterror.Error{Kind: resterror.ENOTFOUND, MessageKey: "user.not_found", Message: "User not found."}, "User not found."},
+		{"unknown key", &resterror.Error{Kind: resterror.ENOTFOUND, MessageKey: "user.gone"}, resterror.MsgNotFound},
+	}
+	for _, tt := range tests {
+		if got := resterror.ErrorMessage(tt.err); got != tt.want {
+			t.Errorf("%s: ErrorMessage()=%q, want %q", tt.name, got, tt.want)
+		}
+	}
+	if !strings.Contains(logs.String(), "unable to render error message") || !strings.Contains(logs.String(), "message_key=user.not_found") {
+		t.Fatalf("logs=%s", logs.String())
+	}
+}
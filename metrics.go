@@ -0,0 +1,30 @@
+package error
+
+import "time"
+
+// MetricsSink receives metrics about the errors handled by a Handler, e.g.
+// to feed Prometheus counters.
+type MetricsSink interface {
+	// IncError counts an error response of the given kind and status.
+	IncError(kind string, status int)
+}
+
+// LatencyObserver is implemented by metrics sinks which also record how long
+// the requests ending in an error took, e.g. for SLO dashboards. It is
+// optional so existing sinks keep working.
+type LatencyObserver interface {
+	// ObserveErrorLatency records the duration of a request which ended in
+	// an error of the given kind and status.
+	ObserveErrorLatency(kind string, status int, d time.Duration)
+}
+
+// observeError reports an error response to sink, if any.
+func observeError(sink MetricsSink, kind string, status int, d time.Duration) {
+	if sink == nil {
+		return
+	}
+	sink.IncError(kind, status)
+	if o, ok := sink.(LatencyObserver); ok {
+		o.ObserveErrorLatency(kind, status, d)
+	}
+}
@@ -0,0 +1,52 @@
+package error_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	resterror "github.com/truescotian/resterror"
+)
+
+type observation struct {
+	kind   string
+	status int
+	d      time.Duration
+}
+
+type fakeSink struct {
+	errors    []observation
+	latencies []observation
+}
+
+func (s *fakeSink) IncError(kind string, status int) {
+	s.errors = append(s.errors, observation{kind: kind, status: status})
+}
+
+func (s *fakeSink) ObserveErrorLatency(kind string, status int, d time.Duration) {
+	s.latencies = append(s.latencies, observation{kind, status, d})
+}
+
+func TestHandler_Metrics(t *testing.T) {
+	sink := &fakeSink{}
+	h := &resterror.Handler{
+		Fn: func(w http.ResponseWriter, r *http.Request) error {
+			time.Sleep(20 * time.Millisecond)
+			return &resterror.Error{Kind: resterror.ENOTFOUND, Status: 404}
+		},
+		Logger:  discard,
+		Metrics: sink,
+	}
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/users/42", nil))
+
+	if len(sink.errors) != 1 || sink.errors[0].kind != resterror.ENOTFOUND || sink.errors[0].status != 404 {
+		t.Fatalf("errors=%+v", sink.errors)
+	}
+	if len(sink.latencies) != 1 {
+		t.Fatalf("latencies=%+v", sink.latencies)
+	}
+	if o := sink.latencies[0]; o.kind != resterror.ENOTFOUND || o.status != 404 || o.d < 20*time.Millisecond {
+		t.Fatalf("latency=%+v", o)
+	}
+}
@@ -0,0 +1,21 @@
+package error
+
+import "net/http"
+
+// WithRouteOp returns a middleware which sets the matched route of the request
+// as the base Op of its context, so that errors created with NewErrorCtx get
+// an Op such as "GET /users/{id}" for free.
+//
+// extract returns the route pattern matched by the router, e.g. chi's
+// RouteContext(r.Context()).RoutePattern(). The method of the request is
+// prepended to it. When extract returns an empty string the Op is left unset.
+func WithRouteOp(extract func(*http.Request) string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if route := extract(r); route != "" {
+				r = r.WithContext(ContextWithOp(r.Context(), r.Method+" "+route))
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,32 @@
+package error_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	resterror "github.com/truescotian/resterror"
+)
+
+func TestWithRouteOp(t *testing.T) {
+	tests := []struct {
+		route string
+		want  string
+	}{
+		{"/users/{id}", "GET /users/{id}"},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		var got *resterror.Error
+		next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			got = resterror.NewErrorCtx(r.Context(), 404, "User not found.", resterror.ENOTFOUND, nil)
+		})
+		extract := func(*http.Request) string { return tt.route }
+
+		r := httptest.NewRequest(http.MethodGet, "/users/42", nil)
+		resterror.WithRouteOp(extract)(next).ServeHTTP(httptest.NewRecorder(), r)
+		if got.Op != tt.want {
+			t.Errorf("route %q: Op=%q, want %q", tt.route, got.Op, tt.want)
+		}
+	}
+}
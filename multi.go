@@ -0,0 +1,83 @@
+package error
+
+import "strings"
+
+// Errors aggregates several failures, such as those of the items of a bulk
+// endpoint or of the steps of a validation, into a single error:
+//
+//	var errs resterror.Errors
+//	for _, u := range users {
+//		errs = errs.Append(s.CreateUser(ctx, u))
+//	}
+//	return errs.ErrorOrNil()
+//
+// Each item keeps its own kind. The response of the aggregate takes its
+// Kind, Status and Message from the item with the highest status class, the
+// first one on ties, and lists every item in its causes.
+type Errors []*Error
+
+var _ ClientError = Errors(nil)
+
+// Append returns es with errs added to it, like the append builtin. Nil
+// errors are skipped, the items of Errors are added one by one and errors
+// which aren't an *Error are coerced to one, see Coerce.
+func (es Errors) Append(errs ...error) Errors {
+	for _, err := range errs {
+		switch err := err.(type) {
+		case nil:
+		case Errors:
+			es = append(es, err...)
+		default:
+			if e := Coerce(err); e != nil {
+				es = append(es, e)
+			}
+		}
+	}
+	return es
+}
+
+// ErrorOrNil returns es as an error, or nil if es is empty, so that an
+// empty Errors is never returned as a non-nil error.
+func (es Errors) ErrorOrNil() error {
+	if len(es) == 0 {
+		return nil
+	}
+	return es
+}
+
+// Error returns the messages of the items of es separated by semicolons.
+func (es Errors) Error() string {
+	msgs := make([]string, len(es))
+	for i, e := range es {
+		msgs[i] = e.Error()
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// Unwrap returns the items of es, so errors.Is and errors.As match any of
+// them, and the helpers of this package resolve the item with the highest
+// status class.
+func (es Errors) Unwrap() []error {
+	errs := make([]error, len(es))
+	for i, e := range es {
+		errs[i] = e
+	}
+	return errs
+}
+
+// ResponseBody returns the combined response body of es, whose causes are
+// the public copies of its items.
+func (es Errors) ResponseBody() ([]byte, error) {
+	return es.asError().ResponseBody()
+}
+
+// ResponseHeaders returns the HTTP status code of es, that of the item with
+// the highest status class, and the headers of the response.
+func (es Errors) ResponseHeaders() (int, map[string]string) {
+	return es.asError().ResponseHeaders()
+}
+
+// asError returns es as an *Error whose causes are its items.
+func (es Errors) asError() *Error {
+	return &Error{Err: es, Causes: es}
+}
@@ -0,0 +1,85 @@
+package error_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http/httptest"
+	"testing"
+
+	resterror "github.com/truescotian/resterror"
+)
+
+func TestErrors_Append(t *testing.T) {
+	var errs resterror.Errors
+	if err := errs.ErrorOrNil(); err != nil {
+		t.Fatalf("ErrorOrNil()=%v, want nil", err)
+	}
+
+	notFound := &resterror.Error{Kind: resterror.ENOTFOUND, Message: "User 1 not found."}
+	invalid := &resterror.Error{Kind: resterror.EINVALID, Message: "Email is invalid."}
+	errs = errs.Append(nil, notFound)
+	errs = errs.Append(resterror.Errors{invalid}, errors.New("boom"))
+	if len(errs) != 3 {
+		t.Fatalf("len=%d, want 3", len(errs))
+	}
+	if got := resterror.ErrorKind(errs[2]); got != resterror.EINTERNAL {
+		t.Fatalf("kind of a plain error=%q", got)
+	}
+
+	err := errs.ErrorOrNil()
+	if !errors.Is(err, invalid) {
+		t.Fatal("errors.Is should match an item")
+	}
+	if want := "<item_does_not_exist> User 1 not found.; <invalid> Email is invalid.; <internal> boom"; err.Error() != want {
+		t.Fatalf("Error()=%q, want %q", err.Error(), want)
+	}
+}
+
+func TestErrors_Response(t *testing.T) {
+	errs := resterror.Errors{
+		{Kind: resterror.ENOTFOUND, Message: "User 1 not found."},
+		{Kind: resterror.EINVALID, Message: "Email is invalid."},
+	}
+
+	w := httptest.NewRecorder()
+	resterror.WriteError(w, errs.ErrorOrNil())
+	if w.Code != 404 {
+		t.Fatalf("status=%d, want 404", w.Code)
+	}
+
+	var body struct {
+		Kind   string `json:"kind"`
+		Causes []struct {
+			Kind    string `json:"kind"`
+			Status  int    `json:"status"`
+			Message string `json:"message"`
+		} `json:"causes"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatal(err)
+	}
+	if body.Kind != resterror.ENOTFOUND || len(body.Causes) != 2 {
+		t.Fatalf("body=%s", w.Body)
+	}
+	if c := body.Causes[1]; c.Kind != resterror.EINVALID || c.Status != 422 || c.Message != "Email is invalid." {
+		t.Fatalf("causes[1]=%+v", c)
+	}
+
+	// A server error among the items decides the status, and its message
+	// isn't leaked.
+	errs = append(errs, &resterror.Error{Kind: resterror.EINTERNAL, Message: "pq: deadlock detected"})
+	status, _ := errs.ResponseHeaders()
+	if status != 500 {
+		t.Fatalf("status=%d, want 500", status)
+	}
+	b, err := errs.ResponseBody()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal(b, &body); err != nil {
+		t.Fatal(err)
+	}
+	if body.Causes[2].Message != resterror.MsgInternal {
+		t.Fatalf("body=%s", b)
+	}
+}
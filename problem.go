@@ -0,0 +1,102 @@
+package error
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+)
+
+// Problem is an RFC 7807 (RFC 9457) problem details object. Validation
+// errors list their fields in the invalid-params extension member, as
+// several API gateways expect, the incident ID of server errors is sent in
+// the incident_id extension member, and the failed items of a BatchError in
+// the items extension member.
+type Problem struct {
+	Type          string         `json:"type"`
+	Title         string         `json:"title"`
+	Status        int            `json:"status"`
+	Detail        string         `json:"detail,omitempty"`
+	Instance      string         `json:"instance,omitempty"`
+	IncidentID    string         `json:"incident_id,omitempty"`
+	InvalidParams []InvalidParam `json:"invalid-params,omitempty"`
+	Items         []BatchItem    `json:"items,omitempty"`
+}
+
+// InvalidParam is a field of a request which failed validation.
+type InvalidParam struct {
+	Name   string `json:"name"`
+	Reason string `json:"reason"`
+}
+
+// ToProblem converts err to a Problem, resolving it with the default
+// registry. See Registry.ToProblem.
+func ToProblem(err error) *Problem {
+	return DefaultRegistry().ToProblem(err)
+}
+
+// ToProblem converts err to a Problem. The type is the DocsURL of err, or
+// "about:blank" if it has none, see SetDocsBaseURL. The detail is the
+// client-safe message of err, and if err wraps a *ValidationError its fields
+// are listed, sorted by name, in InvalidParams.
+//
+// The instance is left empty, Handler sets it to the path of the request.
+func (r *Registry) ToProblem(err error) *Problem {
+	e := r.coerce(err)
+	status := r.ErrorStatus(e)
+	p := &Problem{
+		Type:       "about:blank",
+		Title:      http.StatusText(status),
+		Status:     status,
+		Detail:     r.ClientSafeMessage(e),
+		IncidentID: r.errorIncidentID(e),
+		Items:      r.publicItems(e),
+	}
+	if docs := r.docsURL(e); docs != "" {
+		p.Type = docs
+	}
+
+	if v, ok := as[*ValidationError](err); ok {
+		for name, reason := range v.Fields {
+			p.InvalidParams = append(p.InvalidParams, InvalidParam{Name: name, Reason: reason})
+		}
+		sort.Slice(p.InvalidParams, func(i, j int) bool { return p.InvalidParams[i].Name < p.InvalidParams[j].Name })
+	}
+	return p
+}
+
+// WriteProblem writes err to w as application/problem+json, resolving it
+// with the default registry.
+func WriteProblem(w http.ResponseWriter, err error) {
+	DefaultRegistry().WriteProblem(w, err)
+}
+
+// WriteProblem is like the package-level WriteProblem but resolves err
+// with r.
+func (r *Registry) WriteProblem(w http.ResponseWriter, err error) {
+	r.writeProblem(w, err, "")
+}
+
+// writeProblem writes err to w as application/problem+json, with instance
+// as the instance of the problem.
+func (r *Registry) writeProblem(w http.ResponseWriter, err error, instance string) {
+	e, p := r.coerce(err), r.ToProblem(err)
+	p.Instance = instance
+	body, err := json.Marshal(p)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	status, headers := r.ResponseHeaders(e)
+	for k, v := range headers {
+		w.Header().Set(k, v)
+	}
+	if !bodyAllowed(status) {
+		w.Header().Del("Content-Type")
+		w.WriteHeader(status)
+		return
+	}
+	w.Header().Set("Content-Type", "application/problem+json")
+	w.WriteHeader(status)
+	w.Write(body)
+}
@@ -0,0 +1,62 @@
+package error_test
+
+import (
+	"bytes"
+	"flag"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	resterror "github.com/truescotian/resterror"
+)
+
+var update = flag.Bool("update", false, "update golden files")
+
+func TestWriteProblem(t *testing.T) {
+	tests := []struct {
+		name   string
+		err    error
+		status int
+	}{
+		{
+			name: "validation",
+			err: &resterror.ValidationError{Op: "CreateUser", Fields: map[string]string{
+				"username": "is required",
+				"email":    "must be a valid email address",
+			}},
+			status: 422,
+		},
+		{
+			name:   "not_found",
+			err:    &resterror.Error{Op: "FindUser", Kind: resterror.ENOTFOUND, Status: 404, Message: "User not found."},
+			status: 404,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			resterror.WriteProblem(rec, tt.err)
+			if rec.Code != tt.status {
+				t.Fatalf("status=%d", rec.Code)
+			}
+			if ct := rec.Header().Get("Content-Type"); ct != "application/problem+json" {
+				t.Fatalf("Content-Type=%q", ct)
+			}
+
+			golden := filepath.Join("testdata", "problem_"+tt.name+".golden")
+			if *update {
+				if err := os.WriteFile(golden, rec.Body.Bytes(), 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			want, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(rec.Body.Bytes(), want) {
+				t.Fatalf("body=%s, want %s", rec.Body, want)
+			}
+		})
+	}
+}
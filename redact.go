@@ -0,0 +1,112 @@
+package error
+
+import "errors"
+
+// MarkSensitive returns a copy of e with the given Details and Fields keys
+// flagged as holding customer data: their values are replaced with Redacted
+// in the Redacted copy of the error. The keys may be marked on any error of
+// the chain.
+func (e *Error) MarkSensitive(keys ...string) *Error {
+	c := e.Clone()
+	if c.sensitive == nil {
+		c.sensitive = make(map[string]bool, len(keys))
+	}
+	for _, key := range keys {
+		c.sensitive[key] = true
+	}
+	return c
+}
+
+// Redacted returns a copy of the chain of e which can be forwarded to third
+// parties, such as error trackers, without leaking customer data. See
+// Registry.Redacted.
+func (e *Error) Redacted() *Error {
+	return DefaultRegistry().Redacted(e)
+}
+
+// Redacted returns a copy of the chain of e which can be forwarded to third
+// parties. The Kind, Status, Code, op trace, time and stack of every error
+// are kept, while:
+//
+//   - the messages, Detail and Params of the errors marked Sensitive are
+//     replaced with Redacted, or removed;
+//   - the values of the Details and Fields keys marked with MarkSensitive
+//     are replaced with Redacted;
+//   - the redactors of r are applied to the remaining messages and to the
+//     text of the root cause, which is kept as a plain error, see
+//     WithRedactor.
+func (r *Registry) Redacted(e *Error) *Error {
+	if e == nil {
+		return nil
+	}
+
+	sensitive := make(map[string]bool)
+	r.walk(e, func(e *Error) bool {
+		for key := range e.sensitive {
+			sensitive[key] = true
+		}
+		return true
+	})
+
+	var root, last *Error
+	err := error(e)
+	for depth := 0; err != nil && depth < maxDepth; depth++ {
+		layer, ok := err.(*Error)
+		if !ok {
+			// A standard library wrapper's text includes the messages of
+			// the errors it wraps, so it is skipped in favor of them.
+			if layer, ok = r.asError(err); !ok {
+				last.Err = errors.New(r.redact(err.Error()))
+				break
+			}
+		}
+
+		c := r.redactLayer(layer, sensitive)
+		if root == nil {
+			root = c
+		} else {
+			last.Err = c
+		}
+		last, err = c, layer.Err
+	}
+	return root
+}
+
+// redactLayer returns the redacted copy of e alone, see Redacted. Its Err is
+// left to the caller.
+func (r *Registry) redactLayer(e *Error, sensitive map[string]bool) *Error {
+	c := e.Clone()
+	c.Err = nil
+	if c.Sensitive {
+		c.Message = redactNonEmpty(c.Message)
+		c.UserMessage = redactNonEmpty(c.UserMessage)
+		c.Detail = redactNonEmpty(c.Detail)
+		c.Params = nil
+	} else {
+		c.Message = r.redact(c.Message)
+		c.UserMessage = r.redact(c.UserMessage)
+		c.Detail = r.redact(c.Detail)
+	}
+	for key := range c.Details {
+		if sensitive[key] {
+			c.Details[key] = Redacted
+		}
+	}
+	for key := range c.Fields {
+		if sensitive[key] {
+			c.Fields[key] = Redacted
+		}
+	}
+	for i, cause := range c.Causes {
+		c.Causes[i] = r.Redacted(cause)
+	}
+	return c
+}
+
+// redactNonEmpty returns Redacted, or "" if s is empty.
+func redactNonEmpty(s string) string {
+	if s == "" {
+		return ""
+	}
+	return Redacted
+}
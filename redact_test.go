@@ -0,0 +1,56 @@
+package error_test
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"testing"
+
+	resterror "github.com/truescotian/resterror"
+)
+
+func TestRedacted(t *testing.T) {
+	defer resterror.SetDefaultRegistry(resterror.DefaultRegistry())
+	email := regexp.MustCompile(`[\w.]+@[\w.]+`)
+	resterror.RegisterRedactor(func(s string) string { return email.ReplaceAllString(s, "[EMAIL]") })
+
+	inner := &resterror.Error{
+		Op:        "queryUser",
+		Kind:      resterror.ENOTFOUND,
+		Message:   "No user named Jane Doe.",
+		Sensitive: true,
+		Details:   map[string]interface{}{"name": "Jane Doe", "table": "users"},
+		Err:       errors.New("sql: no rows for jane@example.com"),
+	}
+	outer := (&resterror.Error{Op: "UserService.FindUser", Message: "Lookup of jane@example.com failed."}).
+		WithField("email", "jane@example.com")
+	outer.Err = fmt.Errorf("find: %w", inner)
+	outer = outer.MarkSensitive("name", "email")
+
+	r := outer.Redacted()
+	if got := r.Error(); strings.Contains(got, "jane") || strings.Contains(got, "Jane") {
+		t.Fatalf("Error()=%q leaks customer data", got)
+	}
+	if got := resterror.OpTrace(r); got != "UserService.FindUser: queryUser" {
+		t.Fatalf("OpTrace()=%q", got)
+	}
+	if got := resterror.ErrorKind(r); got != resterror.ENOTFOUND {
+		t.Fatalf("ErrorKind()=%q", got)
+	}
+	if r.Message != "Lookup of [EMAIL] failed." || r.Fields["email"] != resterror.Redacted {
+		t.Fatalf("outer=%+v", r)
+	}
+	in := r.Err.(*resterror.Error)
+	if in.Message != resterror.Redacted || in.Details["name"] != resterror.Redacted || in.Details["table"] != "users" {
+		t.Fatalf("inner=%+v", in)
+	}
+	if got := resterror.Cause(r).Error(); got != "sql: no rows for [EMAIL]" {
+		t.Fatalf("cause=%q", got)
+	}
+
+	// The original chain is left untouched.
+	if inner.Message != "No user named Jane Doe." || inner.Details["name"] != "Jane Doe" || outer.Fields["email"] != "jane@example.com" {
+		t.Fatal("Redacted() modified the original chain")
+	}
+}
@@ -0,0 +1,414 @@
+package error
+
+import (
+	"log/slog"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"text/template"
+	"time"
+	"unicode/utf8"
+)
+
+// Registry bundles the configuration used to resolve and serialize errors:
+// the status of each kind, the status and message used when none is defined,
+// and how response bodies are encoded.
+//
+// A Registry is immutable, its With methods return a modified copy, so it
+// can be shared freely. Tests and multi-tenant servers can use registries of
+// their own, while the package-level functions use the default registry.
+type Registry struct {
+	kinds          map[string]kindInfo
+	defaultStatus  int
+	defaultMessage string
+	indent         bool
+	maxMessageLen  int
+	etags          bool
+	redactors      []func(string) string
+	enrichers      []Enricher
+	joinPolicy     JoinPolicy
+	docsBaseURL    string
+	messages       map[string]*template.Template
+	codes          map[string]int
+	parents        map[string]string
+
+	captureStackFor func(kind string) bool
+	clock           func() time.Time
+	logger          *slog.Logger
+}
+
+// NewRegistry returns a registry holding the builtin kinds with a default
+// status of 500 and a default message of MsgInternal.
+func NewRegistry() *Registry {
+	kinds := make(map[string]kindInfo, len(builtinKinds))
+	for kind, info := range builtinKinds {
+		kinds[kind] = info
+	}
+	return &Registry{
+		kinds:          kinds,
+		defaultStatus:  http.StatusInternalServerError,
+		defaultMessage: MsgInternal,
+	}
+}
+
+// clone returns a copy of r which can be modified.
+func (r *Registry) clone() *Registry {
+	c := *r
+	c.kinds = make(map[string]kindInfo, len(r.kinds))
+	for kind, info := range r.kinds {
+		c.kinds[kind] = info
+	}
+	c.messages = make(map[string]*template.Template, len(r.messages))
+	for key, tmpl := range r.messages {
+		c.messages[key] = tmpl
+	}
+	c.parents = make(map[string]string, len(r.parents))
+	for kind, parent := range r.parents {
+		c.parents[kind] = parent
+	}
+	c.codes = make(map[string]int, len(r.codes))
+	for kind, code := range r.codes {
+		c.codes[kind] = code
+	}
+	return &c
+}
+
+// WithKind returns a copy of r where kind has the given HTTP status code and
+// message. An empty message means the default message of r.
+func (r *Registry) WithKind(kind string, status int, message string) *Registry {
+	c := r.clone()
+	c.kinds[kind] = kindInfo{Status: status, Message: message}
+	return c
+}
+
+// WithCode returns a copy of r where kind has the stable numeric code, see
+// RegisterCode.
+func (r *Registry) WithCode(kind string, code int) *Registry {
+	c := r.clone()
+	c.codes[kind] = code
+	return c
+}
+
+// WithKindParent returns a copy of r where kind descends from parent, see
+// RegisterKindParent.
+func (r *Registry) WithKindParent(kind, parent string) *Registry {
+	c := r.clone()
+	c.parents[kind] = parent
+	return c
+}
+
+// WithDefaultStatus returns a copy of r using status for kinds which have no
+// status of their own.
+func (r *Registry) WithDefaultStatus(status int) *Registry {
+	c := r.clone()
+	c.defaultStatus = status
+	return c
+}
+
+// WithDefaultMessage returns a copy of r using msg when no message is defined
+// in the chain, and for server errors sent to clients.
+func (r *Registry) WithDefaultMessage(msg string) *Registry {
+	c := r.clone()
+	c.defaultMessage = msg
+	return c
+}
+
+// WithIndent returns a copy of r which indents the JSON of response bodies.
+func (r *Registry) WithIndent(indent bool) *Registry {
+	c := r.clone()
+	c.indent = indent
+	return c
+}
+
+// WithETags returns a copy of r which emits an ETag for error responses, see
+// SetETags.
+func (r *Registry) WithETags(enabled bool) *Registry {
+	c := r.clone()
+	c.etags = enabled
+	return c
+}
+
+// WithMaxMessageLen returns a copy of r which truncates messages longer than
+// n runes, in response bodies and logs, see SetMaxMessageLen.
+func (r *Registry) WithMaxMessageLen(n int) *Registry {
+	c := r.clone()
+	c.maxMessageLen = n
+	return c
+}
+
+// WithRedactor returns a copy of r which applies fn to debug dumps, e.g. to
+// mask the emails or tokens in them, see DebugDump.
+func (r *Registry) WithRedactor(fn func(string) string) *Registry {
+	c := r.clone()
+	c.redactors = append(r.redactors[:len(r.redactors):len(r.redactors)], fn)
+	return c
+}
+
+// WithJoinPolicy returns a copy of r which resolves joined errors with
+// policy, see SetJoinPolicy.
+func (r *Registry) WithJoinPolicy(policy JoinPolicy) *Registry {
+	c := r.clone()
+	c.joinPolicy = policy
+	return c
+}
+
+// WithDocsBaseURL returns a copy of r which links errors without a DocsURL
+// to the page of their kind under base, see SetDocsBaseURL.
+func (r *Registry) WithDocsBaseURL(base string) *Registry {
+	c := r.clone()
+	c.docsBaseURL = base
+	return c
+}
+
+// WithMessageTemplate returns a copy of r where the errors whose MessageKey
+// is key render their message with the text/template text, see
+// RegisterMessage. It panics if text isn't a valid template.
+func (r *Registry) WithMessageTemplate(key, text string) *Registry {
+	tmpl := template.Must(template.New(key).Option("missingkey=error").Parse(text))
+	c := r.clone()
+	c.messages[key] = tmpl
+	return c
+}
+
+// WithEnricher returns a copy of r which applies fn to the errors created
+// with a context, see RegisterEnricher.
+func (r *Registry) WithEnricher(fn Enricher) *Registry {
+	c := r.clone()
+	c.enrichers = append(r.enrichers[:len(r.enrichers):len(r.enrichers)], fn)
+	return c
+}
+
+// WithCaptureStackFor returns a copy of r where the constructors, such as
+// NewError and Wrap, capture the call stack of the errors whose kind
+// satisfies fn, see SetCaptureStackFor.
+func (r *Registry) WithCaptureStackFor(fn func(kind string) bool) *Registry {
+	c := r.clone()
+	c.captureStackFor = fn
+	return c
+}
+
+// WithClock returns a copy of r whose constructors timestamp errors with
+// clock, e.g. a fixed time in tests, see SetClock.
+func (r *Registry) WithClock(clock func() time.Time) *Registry {
+	c := r.clone()
+	c.clock = clock
+	return c
+}
+
+// WithLogger returns a copy of r which logs the problems met while writing
+// errors, such as an invalid header, to logger, see SetLogger.
+func (r *Registry) WithLogger(logger *slog.Logger) *Registry {
+	c := r.clone()
+	c.logger = logger
+	return c
+}
+
+// log returns the logger of r, or slog.Default() if it has none.
+func (r *Registry) log() *slog.Logger {
+	if r.logger != nil {
+		return r.logger
+	}
+	return slog.Default()
+}
+
+// now returns the current time of the clock of r.
+func (r *Registry) now() time.Time {
+	if r.clock != nil {
+		return r.clock()
+	}
+	return time.Now()
+}
+
+// truncate returns the first maxMessageLen runes of msg followed by "…" if
+// msg is longer than that. A UTF-8 sequence is never split.
+func (r *Registry) truncate(msg string) string {
+	if r.maxMessageLen <= 0 || utf8.RuneCountInString(msg) <= r.maxMessageLen {
+		return msg
+	}
+	n := 0
+	for i := range msg {
+		if n == r.maxMessageLen {
+			return msg[:i] + "…"
+		}
+		n++
+	}
+	return msg
+}
+
+// StatusForKind returns the HTTP status code of kind in r, or that of its
+// closest ancestor which has one, see RegisterKindParent. Unknown kinds
+// return the default status.
+func (r *Registry) StatusForKind(kind string) int {
+	for _, k := range r.lineage(kind) {
+		if info, ok := r.kinds[k]; ok && info.Status != 0 {
+			return info.Status
+		}
+	}
+	return r.defaultStatus
+}
+
+// KindMatches reports whether kind is target or descends from it in r,
+// see RegisterKindParent.
+func (r *Registry) KindMatches(kind, target string) bool {
+	for _, k := range r.lineage(kind) {
+		if k == target {
+			return true
+		}
+	}
+	return false
+}
+
+// lineage returns kind followed by its ancestors in r, closest first. An
+// accidental cycle ends the lineage.
+func (r *Registry) lineage(kind string) []string {
+	kinds := []string{kind}
+	for len(kinds) < maxDepth {
+		parent, ok := r.parents[kinds[len(kinds)-1]]
+		if !ok || parent == kind {
+			break
+		}
+		kinds = append(kinds, parent)
+	}
+	return kinds
+}
+
+// CodeForKind returns the numeric code of kind in r, or 0 if it has none.
+func (r *Registry) CodeForKind(kind string) int {
+	return r.codes[kind]
+}
+
+// MessageForKind returns the message of kind in r, or the default message
+// for unknown kinds and kinds without a message.
+func (r *Registry) MessageForKind(kind string) string {
+	for _, k := range r.lineage(kind) {
+		if info, ok := r.kinds[k]; ok && info.Message != "" {
+			return info.Message
+		}
+	}
+	return r.defaultMessage
+}
+
+// ErrorMessage is like the package-level ErrorMessage but resolves err
+// with r.
+func (r *Registry) ErrorMessage(err error) string {
+	if err == nil {
+		return ""
+	} else if e, ok := r.lookup(err, hasMessage); ok {
+		return r.message(e)
+	}
+	return r.MessageForKind(r.errorKind(err))
+}
+
+var (
+	// defaultRegistryMu serializes the package-level setters.
+	defaultRegistryMu sync.Mutex
+	defaultRegistry   atomic.Pointer[Registry]
+)
+
+func init() {
+	defaultRegistry.Store(NewRegistry())
+}
+
+// DefaultRegistry returns the registry used by the package-level functions.
+func DefaultRegistry() *Registry {
+	return defaultRegistry.Load()
+}
+
+// SetDefaultRegistry replaces the registry used by the package-level
+// functions.
+func SetDefaultRegistry(r *Registry) {
+	defaultRegistryMu.Lock()
+	defer defaultRegistryMu.Unlock()
+	defaultRegistry.Store(r)
+}
+
+// updateDefaultRegistry replaces the default registry with the result of fn.
+func updateDefaultRegistry(fn func(*Registry) *Registry) {
+	defaultRegistryMu.Lock()
+	defer defaultRegistryMu.Unlock()
+	defaultRegistry.Store(fn(defaultRegistry.Load()))
+}
+
+// SetDefaultStatus sets the HTTP status code used for kinds which have no
+// status of their own. It defaults to 500.
+//
+// Builtin kinds are not affected.
+func SetDefaultStatus(status int) {
+	updateDefaultRegistry(func(r *Registry) *Registry { return r.WithDefaultStatus(status) })
+}
+
+// SetDefaultMessage sets the message returned by ErrorMessage when no message
+// is defined in the chain, and by ClientSafeMessage for server errors.
+// It defaults to MsgInternal.
+func SetDefaultMessage(msg string) {
+	updateDefaultRegistry(func(r *Registry) *Registry { return r.WithDefaultMessage(msg) })
+}
+
+// SetIndent sets whether ResponseBody indents the JSON it returns, which
+// makes responses easier to read while debugging. It defaults to false so
+// production responses stay compact.
+func SetIndent(enabled bool) {
+	updateDefaultRegistry(func(r *Registry) *Registry { return r.WithIndent(enabled) })
+}
+
+// SetMaxMessageLen sets the maximum length, in runes, of the messages written
+// in response bodies and logs. Longer messages, such as those of some driver
+// errors, are truncated and end with "…". It defaults to 0, i.e. unlimited.
+func SetMaxMessageLen(n int) {
+	updateDefaultRegistry(func(r *Registry) *Registry { return r.WithMaxMessageLen(n) })
+}
+
+// RegisterRedactor adds fn to the redactors applied to debug dumps, see
+// DebugDump.
+func RegisterRedactor(fn func(string) string) {
+	updateDefaultRegistry(func(r *Registry) *Registry { return r.WithRedactor(fn) })
+}
+
+// SetCaptureStackFor sets which errors capture their call stack when created
+// by a constructor, such as NewError or Wrap, see Error.Frames. A nil fn
+// restores the default, which only captures the stack of server errors, i.e.
+// kinds whose status is 5xx, so routine client errors such as 404s skip the
+// cost of runtime.Callers. To capture the stack of every error:
+//
+//	resterror.SetCaptureStackFor(func(string) bool { return true })
+func SetCaptureStackFor(fn func(kind string) bool) {
+	updateDefaultRegistry(func(r *Registry) *Registry { return r.WithCaptureStackFor(fn) })
+}
+
+// SetETags sets whether ResponseHeaders emits an ETag derived from the body
+// of the response, so that caching proxies can revalidate stable errors such
+// as a missing resource. Handler answers a GET or HEAD request whose
+// If-None-Match lists it with a 304. It defaults to false.
+func SetETags(enabled bool) {
+	updateDefaultRegistry(func(r *Registry) *Registry { return r.WithETags(enabled) })
+}
+
+// SetClock sets the clock the constructors timestamp errors with, see
+// Error.Time. A nil clock restores time.Now.
+func SetClock(clock func() time.Time) {
+	updateDefaultRegistry(func(r *Registry) *Registry { return r.WithClock(clock) })
+}
+
+// SetLogger sets the logger of the problems met while writing errors, such
+// as a response body which can't be marshaled or an invalid header. It is
+// also the logger of a Handler without a Logger of its own. A nil logger
+// restores slog.Default().
+func SetLogger(logger *slog.Logger) {
+	updateDefaultRegistry(func(r *Registry) *Registry { return r.WithLogger(logger) })
+}
+
+// SetJoinPolicy sets which branch of a joined error, such as those of
+// errors.Join, ErrorKind, ErrorMessage, Is, Coerce and Handler resolve the
+// error from. It defaults to JoinHighestStatus.
+func SetJoinPolicy(policy JoinPolicy) {
+	updateDefaultRegistry(func(r *Registry) *Registry { return r.WithJoinPolicy(policy) })
+}
+
+// SetDocsBaseURL sets the base URL of the documentation of the error kinds.
+// The response of an error without a DocsURL links to the page of its kind
+// under base, e.g. with "https://docs.example.com/errors" an ENOTFOUND links
+// to "https://docs.example.com/errors/item_does_not_exist". It defaults to
+// "", i.e. no link.
+func SetDocsBaseURL(base string) {
+	updateDefaultRegistry(func(r *Registry) *Registry { return r.WithDocsBaseURL(base) })
+}
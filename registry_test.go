@@ -0,0 +1,101 @@
+package error_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	resterror "github.com/truescotian/resterror"
+)
+
+func TestRegistry(t *testing.T) {
+	base := resterror.NewRegistry()
+	branded := base.
+		WithKind("teapot", http.StatusTeapot, "").
+		WithDefaultStatus(http.StatusBadRequest).
+		WithDefaultMessage("Oops, our team is on it.")
+
+	if got := branded.StatusForKind("teapot"); got != http.StatusTeapot {
+		t.Fatalf("branded StatusForKind(teapot)=%d", got)
+	}
+	if got := branded.StatusForKind("unknown"); got != http.StatusBadRequest {
+		t.Fatalf("branded StatusForKind(unknown)=%d", got)
+	}
+
+	// Neither the original registry nor the default one are modified.
+	for _, r := range []*resterror.Registry{base, resterror.DefaultRegistry()} {
+		if got := r.StatusForKind("teapot"); got != http.StatusInternalServerError {
+			t.Fatalf("StatusForKind(teapot)=%d", got)
+		}
+		if got := r.ErrorMessage(&resterror.Error{}); got != resterror.MsgInternal {
+			t.Fatalf("ErrorMessage()=%q", got)
+		}
+	}
+
+	e := &resterror.Error{Kind: resterror.EINTERNAL, Message: "pq: connection refused"}
+	body, _ := branded.ResponseBody(e)
+	if want := `{"kind":"internal","status":500,"message":"Oops, our team is on it."}`; string(body) != want {
+		t.Fatalf("branded body=%s", body)
+	}
+	body, _ = base.ResponseBody(e)
+	if want := `{"kind":"internal","status":500,"message":"` + resterror.MsgInternal + `"}`; string(body) != want {
+		t.Fatalf("base body=%s", body)
+	}
+}
+
+func TestHandler_Registry(t *testing.T) {
+	h := &resterror.Handler{
+		Fn: func(w http.ResponseWriter, r *http.Request) error {
+			return &resterror.Error{Kind: "teapot", Message: "I'm a teapot."}
+		},
+		Logger:   discard,
+		Registry: resterror.NewRegistry().WithKind("teapot", http.StatusTeapot, ""),
+	}
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/coffee", nil))
+	if rec.Code != http.StatusTeapot {
+		t.Fatalf("status=%d", rec.Code)
+	}
+}
+
+func TestRegistry_MaxMessageLen(t *testing.T) {
+	r := resterror.NewRegistry().WithMaxMessageLen(10)
+	msg := strings.Repeat("日本語のエラー", 1000)
+	e := &resterror.Error{Kind: resterror.EINVALID, Message: msg, Err: errors.New(msg)}
+
+	body, err := r.ResponseBody(e)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got struct{ Message string }
+	if err := json.Unmarshal(body, &got); err != nil {
+		t.Fatal(err)
+	}
+	if want := "日本語のエラー日本語…"; got.Message != want {
+		t.Fatalf("message=%q, want %q", got.Message, want)
+	}
+
+	for _, a := range r.LogAttrs(e) {
+		if a.Key != "cause" {
+			continue
+		}
+		cause := a.Value.String()
+		if !utf8.ValidString(cause) || utf8.RuneCountInString(cause) != 11 {
+			t.Fatalf("cause=%q", cause)
+		}
+	}
+
+	// Short messages and the default registry are left untouched.
+	e = &resterror.Error{Kind: resterror.EINVALID, Message: "日本語"}
+	if got := r.Public(e).Message; got != "日本語" {
+		t.Fatalf("short message=%q", got)
+	}
+	if got := resterror.DefaultRegistry().Public(&resterror.Error{Kind: resterror.EINVALID, Message: msg}).Message; got != msg {
+		t.Fatal("default registry truncated the message")
+	}
+}
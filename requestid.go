@@ -0,0 +1,64 @@
+package error
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"net/http"
+)
+
+// HeaderRequestID is the header carrying the ID of a request, see
+// Handler.IDGenerator.
+const HeaderRequestID = "X-Request-ID"
+
+// maxRequestIDLen caps the length of the request IDs accepted from clients.
+const maxRequestIDLen = 128
+
+// IDGenerator generates the IDs of requests, such as UUIDs or ULIDs.
+type IDGenerator interface {
+	NewID() string
+}
+
+// RequestIDDeriver may be implemented by an IDGenerator to derive the ID
+// from the incoming request, e.g. from its trace context. When it returns
+// false, NewID is used instead.
+type RequestIDDeriver interface {
+	DeriveID(r *http.Request) (string, bool)
+}
+
+// UUIDGenerator generates random (version 4) UUIDs.
+type UUIDGenerator struct{}
+
+// NewID returns a new random UUID.
+func (UUIDGenerator) NewID() string {
+	var b [16]byte
+	rand.Read(b[:])
+	b[6] = b[6]&0x0f | 0x40 // Version 4.
+	b[8] = b[8]&0x3f | 0x80 // Variant 10.
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+}
+
+// requestID returns the ID of r: the one sent by the client if it is valid,
+// otherwise one derived from r or a new one.
+func requestID(gen IDGenerator, r *http.Request) string {
+	if id, ok := sanitizeHeaderValue(r.Header.Get(HeaderRequestID)); ok && id != "" && len(id) <= maxRequestIDLen {
+		return id
+	}
+	if d, ok := gen.(RequestIDDeriver); ok {
+		if id, ok := d.DeriveID(r); ok {
+			return id
+		}
+	}
+	return gen.NewID()
+}
+
+// ContextWithRequestID returns a copy of ctx carrying the request ID id.
+func ContextWithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDContextKey, id)
+}
+
+// RequestIDFromContext returns the request ID carried by ctx, if any.
+func RequestIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDContextKey).(string)
+	return id
+}
@@ -0,0 +1,76 @@
+package error_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+
+	resterror "github.com/truescotian/resterror"
+)
+
+// traceGenerator derives the request ID from the W3C trace context, or
+// generates sequential IDs.
+type traceGenerator struct{ n int }
+
+func (g *traceGenerator) NewID() string {
+	g.n++
+	return "req-" + strings.Repeat("x", g.n)
+}
+
+func (g *traceGenerator) DeriveID(r *http.Request) (string, bool) {
+	parts := strings.Split(r.Header.Get("traceparent"), "-")
+	if len(parts) != 4 {
+		return "", false
+	}
+	return parts[1], true
+}
+
+func TestHandler_IDGenerator(t *testing.T) {
+	var fromCtx string
+	h := &resterror.Handler{
+		Fn: func(w http.ResponseWriter, r *http.Request) error {
+			fromCtx = resterror.RequestIDFromContext(r.Context())
+			return &resterror.Error{Kind: resterror.ENOTFOUND, Status: 404}
+		},
+		Logger:      discard,
+		IDGenerator: &traceGenerator{},
+	}
+
+	tests := []struct {
+		name   string
+		header string
+		value  string
+		want   string
+	}{
+		{"generated", "", "", "req-x"},
+		{"trace context", "traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", "4bf92f3577b34da6a3ce929d0e0e4736"},
+		{"incoming", "X-Request-ID", "abc-123", "abc-123"},
+		{"invalid incoming", "X-Request-ID", strings.Repeat("a", 200), "req-xx"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.header != "" {
+				req.Header.Set(tt.header, tt.value)
+			}
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, req)
+			if got := rec.Header().Get(resterror.HeaderRequestID); got != tt.want {
+				t.Fatalf("X-Request-ID=%q, want %q", got, tt.want)
+			}
+			if fromCtx != tt.want {
+				t.Fatalf("RequestIDFromContext()=%q, want %q", fromCtx, tt.want)
+			}
+		})
+	}
+}
+
+func TestUUIDGenerator(t *testing.T) {
+	re := regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)
+	a, b := resterror.UUIDGenerator{}.NewID(), resterror.UUIDGenerator{}.NewID()
+	if !re.MatchString(a) || a == b {
+		t.Fatalf("NewID()=%q, %q", a, b)
+	}
+}
@@ -0,0 +1,70 @@
+// Package restgateway writes the errors of gRPC services served through
+// gRPC-Gateway with the JSON body of resterror:
+//
+//	mux := runtime.NewServeMux(runtime.WithErrorHandler(restgateway.HTTPErrorHandler()))
+//
+// Services carry the kind of their errors in an errdetails.ErrorInfo of the
+// Domain domain, whose Reason is the kind. Errors without one are mapped by
+// their gRPC code.
+package restgateway
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	resterror "github.com/truescotian/resterror"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Domain is the domain of the errdetails.ErrorInfo carrying the kind of an
+// error.
+const Domain = "resterror"
+
+// codeKinds maps gRPC codes to the kind of errors which don't carry one.
+var codeKinds = map[codes.Code]string{
+	codes.InvalidArgument:    resterror.EINVALID,
+	codes.OutOfRange:         resterror.EINVALID,
+	codes.NotFound:           resterror.ENOTFOUND,
+	codes.AlreadyExists:      resterror.EEXIST,
+	codes.Aborted:            resterror.ECONFLICT,
+	codes.FailedPrecondition: resterror.ECONFLICT,
+	codes.PermissionDenied:   resterror.PERMISSION,
+	codes.Unimplemented:      resterror.MethodNotAllowed,
+	codes.Internal:           resterror.EINTERNAL,
+	codes.Unknown:            resterror.EINTERNAL,
+	codes.DataLoss:           resterror.EINTERNAL,
+}
+
+// HTTPErrorHandler returns a gRPC-Gateway error handler writing errors with
+// resterror.WriteError.
+func HTTPErrorHandler() runtime.ErrorHandlerFunc {
+	return func(ctx context.Context, mux *runtime.ServeMux, m runtime.Marshaler, w http.ResponseWriter, r *http.Request, err error) {
+		resterror.WriteError(w, FromStatus(status.Convert(err)))
+	}
+}
+
+// FromStatus returns the *resterror.Error of s.
+//
+// If s carries a kind, the status is the status of that kind. Otherwise the
+// kind is resolved from the code of s, and the status is the one
+// gRPC-Gateway uses for that code.
+func FromStatus(s *status.Status) *resterror.Error {
+	e := &resterror.Error{Message: s.Message(), Err: s.Err()}
+	for _, d := range s.Details() {
+		if info, ok := d.(*errdetails.ErrorInfo); ok && info.GetDomain() == Domain && info.GetReason() != "" {
+			e.Kind = info.GetReason()
+			return e
+		}
+	}
+
+	e.Status = runtime.HTTPStatusFromCode(s.Code())
+	if kind, ok := codeKinds[s.Code()]; ok {
+		e.Kind = kind
+	} else {
+		e.Kind = resterror.KindForStatus(e.Status)
+	}
+	return e
+}
@@ -0,0 +1,82 @@
+package restgateway_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	resterror "github.com/truescotian/resterror"
+	"github.com/truescotian/resterror/restgateway"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func withKind(t *testing.T, code codes.Code, msg, kind string) error {
+	t.Helper()
+	s, err := status.New(code, msg).WithDetails(&errdetails.ErrorInfo{Domain: restgateway.Domain, Reason: kind})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s.Err()
+}
+
+func TestHTTPErrorHandler(t *testing.T) {
+	tests := []struct {
+		name   string
+		err    error
+		status int
+		body   string
+	}{
+		{
+			name:   "embedded kind",
+			err:    withKind(t, codes.InvalidArgument, "Email is taken.", resterror.EEXIST),
+			status: http.StatusConflict,
+			body:   `{"kind":"item_already_exists","status":409,"message":"Email is taken."}`,
+		},
+		{
+			name:   "not found",
+			err:    status.Error(codes.NotFound, "User not found."),
+			status: http.StatusNotFound,
+			body:   `{"kind":"item_does_not_exist","status":404,"message":"User not found."}`,
+		},
+		{
+			name:   "invalid argument",
+			err:    status.Error(codes.InvalidArgument, "Invalid page token."),
+			status: http.StatusBadRequest,
+			body:   `{"kind":"invalid","status":400,"message":"Invalid page token."}`,
+		},
+		{
+			name:   "permission denied",
+			err:    status.Error(codes.PermissionDenied, "Not your project."),
+			status: http.StatusForbidden,
+			body:   `{"kind":"permission","status":403,"message":"Not your project."}`,
+		},
+		{
+			name:   "internal",
+			err:    status.Error(codes.Internal, "pq: connection refused"),
+			status: http.StatusInternalServerError,
+			body:   `{"kind":"internal","status":500,"message":"An internal error has occurred. Please contact technical support."}`,
+		},
+		{
+			name:   "unavailable",
+			err:    status.Error(codes.Unavailable, "dial tcp: connection refused"),
+			status: http.StatusServiceUnavailable,
+			body:   `{"kind":"internal","status":503,"message":"An internal error has occurred. Please contact technical support."}`,
+		},
+	}
+	h := restgateway.HTTPErrorHandler()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			h(context.Background(), nil, nil, rec, httptest.NewRequest(http.MethodGet, "/", nil), tt.err)
+			if rec.Code != tt.status {
+				t.Fatalf("status=%d, want %d", rec.Code, tt.status)
+			}
+			if got := rec.Body.String(); got != tt.body {
+				t.Fatalf("body=%s, want %s", got, tt.body)
+			}
+		})
+	}
+}
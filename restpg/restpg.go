@@ -0,0 +1,76 @@
+// Package restpg translates Postgres errors into a *resterror.Error of the
+// matching kind.
+//
+// Both lib/pq and pgx errors are supported without depending on either
+// driver, through the SQLState method they implement.
+package restpg
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"strings"
+
+	resterror "github.com/truescotian/resterror"
+)
+
+// SQLSTATE codes mapped to a kind.
+// See https://www.postgresql.org/docs/current/errcodes-appendix.html.
+const (
+	UniqueViolation     = "23505"
+	ForeignKeyViolation = "23503"
+	NotNullViolation    = "23502"
+	CheckViolation      = "23514"
+	TooManyConnections  = "53300"
+)
+
+// Messages of the mapped errors. The messages of Postgres name our tables and
+// constraints, so they are never sent to clients.
+const (
+	MsgExists     = "Item already exists."
+	MsgReferenced = "Item references or is referenced by another item."
+)
+
+// sqlStater is implemented by *pq.Error and *pgconn.PgError.
+type sqlStater interface {
+	SQLState() string
+}
+
+// FromPQError returns err as a *resterror.Error of op, with a kind and status
+// resolved from the SQLSTATE of the Postgres error in its chain:
+//
+//   - 23505 unique_violation: EEXIST, 409.
+//   - 23503 foreign_key_violation: ECONFLICT, 409.
+//   - 23502 not_null_violation and 23514 check_violation: EINVALID, 422.
+//   - Connection exceptions (class 08), too many connections and operator
+//     interventions (class 57P) and network errors: transient EINTERNAL.
+//
+// Any other error, including non-Postgres ones, is an EINTERNAL. Returns nil
+// if err is nil.
+func FromPQError(op string, err error) *resterror.Error {
+	if err == nil {
+		return nil
+	}
+	e := &resterror.Error{Op: op, Kind: resterror.EINTERNAL, Status: http.StatusInternalServerError, Err: err}
+
+	var pgErr sqlStater
+	if errors.As(err, &pgErr) {
+		switch code := pgErr.SQLState(); {
+		case code == UniqueViolation:
+			e.Kind, e.Status, e.Message = resterror.EEXIST, http.StatusConflict, MsgExists
+		case code == ForeignKeyViolation:
+			e.Kind, e.Status, e.Message = resterror.ECONFLICT, http.StatusConflict, MsgReferenced
+		case code == NotNullViolation, code == CheckViolation:
+			e.Kind, e.Status, e.Message = resterror.EINVALID, http.StatusUnprocessableEntity, resterror.MsgValidation
+		case strings.HasPrefix(code, "08"), strings.HasPrefix(code, "57P"), code == TooManyConnections:
+			e.Transient = true
+		}
+		return e
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		e.Transient = true
+	}
+	return e
+}
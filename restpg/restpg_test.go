@@ -0,0 +1,56 @@
+package restpg_test
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"testing"
+
+	resterror "github.com/truescotian/resterror"
+	"github.com/truescotian/resterror/restpg"
+)
+
+// pgError fabricates a driver error, like *pq.Error or *pgconn.PgError.
+type pgError struct {
+	code string
+}
+
+func (e *pgError) Error() string    { return "pq: " + e.code }
+func (e *pgError) SQLState() string { return e.code }
+
+func TestFromPQError(t *testing.T) {
+	tests := []struct {
+		name      string
+		err       error
+		kind      string
+		status    int
+		transient bool
+	}{
+		{"unique violation", &pgError{"23505"}, resterror.EEXIST, 409, false},
+		{"foreign key violation", &pgError{"23503"}, resterror.ECONFLICT, 409, false},
+		{"not null violation", &pgError{"23502"}, resterror.EINVALID, 422, false},
+		{"check violation", &pgError{"23514"}, resterror.EINVALID, 422, false},
+		{"wrapped", fmt.Errorf("insert user: %w", &pgError{"23505"}), resterror.EEXIST, 409, false},
+		{"connection failure", &pgError{"08006"}, resterror.EINTERNAL, 500, true},
+		{"admin shutdown", &pgError{"57P01"}, resterror.EINTERNAL, 500, true},
+		{"too many connections", &pgError{"53300"}, resterror.EINTERNAL, 500, true},
+		{"network", &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")}, resterror.EINTERNAL, 500, true},
+		{"syntax error", &pgError{"42601"}, resterror.EINTERNAL, 500, false},
+		{"not postgres", errors.New("boom"), resterror.EINTERNAL, 500, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := restpg.FromPQError("CreateUser", tt.err)
+			if e.Kind != tt.kind || e.Status != tt.status || e.Transient != tt.transient {
+				t.Fatalf("got kind=%q status=%d transient=%t", e.Kind, e.Status, e.Transient)
+			}
+			if e.Op != "CreateUser" || e.Err != tt.err {
+				t.Fatalf("got op=%q err=%v", e.Op, e.Err)
+			}
+		})
+	}
+
+	if e := restpg.FromPQError("CreateUser", nil); e != nil {
+		t.Fatalf("FromPQError(nil)=%v", e)
+	}
+}
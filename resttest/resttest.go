@@ -0,0 +1,36 @@
+// Package resttest provides fake errors for table-driven tests exercising
+// error handling code.
+package resttest
+
+import (
+	"fmt"
+
+	resterror "github.com/truescotian/resterror"
+)
+
+// Fake returns a fully populated *Error of the given kind, with the default
+// status of the kind, a message and an op.
+func Fake(kind string) *resterror.Error {
+	return &resterror.Error{
+		Kind:    kind,
+		Status:  resterror.StatusForKind(kind),
+		Message: fmt.Sprintf("Fake %s error.", kind),
+		Op:      "resttest.Fake",
+	}
+}
+
+// FakeChain returns a chain of fake errors, built the way it would be
+// returned up a call stack: the first kind is the root of the chain and each
+// following kind wraps the previous one. Returns nil if no kind is given.
+func FakeChain(kinds ...string) *resterror.Error {
+	var chain *resterror.Error
+	for i, kind := range kinds {
+		e := Fake(kind)
+		e.Op = fmt.Sprintf("resttest.FakeChain[%d]", i)
+		if chain != nil {
+			e.Err = chain
+		}
+		chain = e
+	}
+	return chain
+}
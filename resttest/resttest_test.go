@@ -0,0 +1,31 @@
+package resttest_test
+
+import (
+	"testing"
+
+	resterror "github.com/truescotian/resterror"
+	"github.com/truescotian/resterror/resttest"
+)
+
+func TestFake(t *testing.T) {
+	e := resttest.Fake(resterror.ENOTFOUND)
+	if e.Kind != resterror.ENOTFOUND || e.Status != 404 || e.Message == "" || e.Op == "" {
+		t.Fatalf("Fake()=%#v", e)
+	}
+}
+
+func TestFakeChain(t *testing.T) {
+	chain := resttest.FakeChain(resterror.EINTERNAL, resterror.EINVALID)
+	if got := resterror.ErrorKind(chain); got != resterror.EINVALID {
+		t.Fatalf("ErrorKind()=%q", got)
+	}
+	if got := resterror.OpTrace(chain); got != "resttest.FakeChain[1]: resttest.FakeChain[0]" {
+		t.Fatalf("OpTrace()=%q", got)
+	}
+	if root, ok := resterror.Cause(chain).(*resterror.Error); !ok || root.Kind != resterror.EINTERNAL {
+		t.Fatalf("Cause()=%v", root)
+	}
+	if resttest.FakeChain() != nil {
+		t.Fatal("FakeChain() should be nil")
+	}
+}
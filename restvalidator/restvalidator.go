@@ -0,0 +1,80 @@
+// Package restvalidator translates the errors of go-playground/validator into
+// a *resterror.ValidationError.
+//
+// The field names reported by validator are the Go field names unless a tag
+// name func is registered on the validator. Register JSONTagName so the
+// fields are keyed by their JSON name, as clients know them:
+//
+//	validate := validator.New()
+//	validate.RegisterTagNameFunc(restvalidator.JSONTagName)
+package restvalidator
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+	resterror "github.com/truescotian/resterror"
+)
+
+// JSONTagName returns the name of fld in its JSON tag. Fields without a JSON
+// tag, or with an empty name in it, return an empty name so validator falls
+// back to the Go field name.
+func JSONTagName(fld reflect.StructField) string {
+	name, _, _ := strings.Cut(fld.Tag.Get("json"), ",")
+	if name == "-" {
+		return ""
+	}
+	return name
+}
+
+// FromValidator returns the field errors of a validator.ValidationErrors
+// found in err as a *resterror.ValidationError, mapping each field to a
+// human-readable reason. Fields of nested structs are keyed by their path,
+// e.g. "address.city".
+//
+// Returns nil if err holds no validator.ValidationErrors.
+func FromValidator(err error) *resterror.ValidationError {
+	var verrs validator.ValidationErrors
+	if !errors.As(err, &verrs) || len(verrs) == 0 {
+		return nil
+	}
+
+	v := &resterror.ValidationError{Fields: make(map[string]string, len(verrs))}
+	for _, fe := range verrs {
+		name := fe.Field()
+		// Namespace is prefixed by the name of the validated struct.
+		if _, path, ok := strings.Cut(fe.Namespace(), "."); ok {
+			name = path
+		}
+		if prev, ok := v.Fields[name]; ok {
+			v.Fields[name] = prev + "; " + reason(fe)
+		} else {
+			v.Fields[name] = reason(fe)
+		}
+	}
+	return v
+}
+
+// reason returns a human-readable reason for the failed validation of fe.
+func reason(fe validator.FieldError) string {
+	switch fe.Tag() {
+	case "required":
+		return "is required"
+	case "email":
+		return "must be a valid email address"
+	case "url":
+		return "must be a valid URL"
+	case "min":
+		return fmt.Sprintf("must be at least %s", fe.Param())
+	case "max":
+		return fmt.Sprintf("must be at most %s", fe.Param())
+	case "len":
+		return fmt.Sprintf("must have a length of %s", fe.Param())
+	case "oneof":
+		return fmt.Sprintf("must be one of %s", fe.Param())
+	}
+	return fmt.Sprintf("failed on the %q rule", fe.Tag())
+}
@@ -0,0 +1,51 @@
+package restvalidator_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/truescotian/resterror/restvalidator"
+)
+
+type address struct {
+	City string `json:"city" validate:"required"`
+}
+
+type user struct {
+	Username string  `json:"username" validate:"required"`
+	Email    string  `json:"email,omitempty" validate:"email"`
+	Age      int     `validate:"min=13"`
+	Address  address `json:"address"`
+}
+
+func TestFromValidator(t *testing.T) {
+	validate := validator.New()
+	validate.RegisterTagNameFunc(restvalidator.JSONTagName)
+
+	err := validate.Struct(user{Email: "gopher", Age: 7})
+	v := restvalidator.FromValidator(err)
+	if v == nil {
+		t.Fatalf("FromValidator(%v)=nil", err)
+	}
+
+	want := map[string]string{
+		"username":     "is required",
+		"email":        "must be a valid email address",
+		"Age":          "must be at least 13",
+		"address.city": "is required",
+	}
+	if !reflect.DeepEqual(v.Fields, want) {
+		t.Fatalf("Fields=%v", v.Fields)
+	}
+}
+
+func TestFromValidator_Other(t *testing.T) {
+	if v := restvalidator.FromValidator(errors.New("boom")); v != nil {
+		t.Fatalf("FromValidator()=%v", v)
+	}
+	if v := restvalidator.FromValidator(nil); v != nil {
+		t.Fatalf("FromValidator(nil)=%v", v)
+	}
+}
@@ -0,0 +1,59 @@
+package error
+
+import (
+	"net"
+	"net/http"
+)
+
+// IsTransient reports whether an *Error in the chain of err is marked as
+// Transient.
+func IsTransient(err error) bool {
+	_, ok := lookup(err, func(e *Error) bool { return e.Transient })
+	return ok
+}
+
+// IsRetryable reports whether the operation which failed with err is worth
+// retrying. In order:
+//
+//   - the first Retryable found in the chain decides, see WithRetryable;
+//   - transient errors are, even internal ones;
+//   - if the chain has a Kind or a Status, errors whose status is 408, 429,
+//     502, 503 or 504 are, other client errors such as EINVALID aren't;
+//   - wrapped causes which report a Timeout() or are Temporary(), such as a
+//     net.Error or context.DeadlineExceeded, are.
+func IsRetryable(err error) bool {
+	if err == nil {
+		return false
+	}
+	if e, ok := lookup(err, func(e *Error) bool { return e.Retryable != nil }); ok {
+		return *e.Retryable
+	}
+	if IsTransient(err) {
+		return true
+	}
+	if _, ok := lookup(err, func(e *Error) bool { return e.Kind != "" || e.Status != 0 }); ok {
+		switch status := ErrorStatus(err); {
+		case retryableStatus(status):
+			return true
+		case status < 500:
+			return false
+		}
+	}
+
+	if ne, ok := as[net.Error](err); ok && ne.Timeout() {
+		return true
+	}
+	temp, ok := as[interface{ Temporary() bool }](err)
+	return ok && temp.Temporary()
+}
+
+// retryableStatus reports whether a request which failed with status may
+// succeed if sent again.
+func retryableStatus(status int) bool {
+	switch status {
+	case http.StatusRequestTimeout, http.StatusTooManyRequests,
+		http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
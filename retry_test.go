@@ -0,0 +1,61 @@
+package error_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"testing"
+
+	resterror "github.com/truescotian/resterror"
+)
+
+func TestIsRetryable_Transient(t *testing.T) {
+	blip := &resterror.Error{
+		Op:  "UserService.FindUserByID",
+		Err: &resterror.Error{Op: "queryUser", Kind: resterror.EINTERNAL, Transient: true, Err: errors.New("pq: connection reset by peer")},
+	}
+	bug := &resterror.Error{Op: "UserService.FindUserByID", Kind: resterror.EINTERNAL, Err: errors.New("nil pointer dereference")}
+
+	if !resterror.IsTransient(blip) || !resterror.IsRetryable(blip) {
+		t.Fatal("transient error should be retryable")
+	}
+	if resterror.IsTransient(bug) || resterror.IsRetryable(bug) {
+		t.Fatal("permanent error shouldn't be retryable")
+	}
+	if resterror.IsRetryable(nil) {
+		t.Fatal("nil error shouldn't be retryable")
+	}
+}
+
+func TestIsRetryable(t *testing.T) {
+	timeout := &net.OpError{Op: "dial", Net: "tcp", Err: os.ErrDeadlineExceeded}
+
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"unavailable", &resterror.Error{Status: 503}, true},
+		{"quota", &resterror.Error{Kind: resterror.EQUOTA}, true},
+		{"invalid", &resterror.Error{Kind: resterror.EINVALID}, false},
+		{"invalid wrapping a timeout", &resterror.Error{Kind: resterror.EINVALID, Err: timeout}, false},
+		{"internal", &resterror.Error{Kind: resterror.EINTERNAL}, false},
+		{"internal wrapping a timeout", &resterror.Error{Kind: resterror.EINTERNAL, Err: timeout}, true},
+		{"wrapped timeout", resterror.Wrap(timeout, "UserService.FindUserByID"), true},
+		{"deadline", fmt.Errorf("query: %w", context.DeadlineExceeded), true},
+		{"plain", errors.New("boom"), false},
+		{"override", (&resterror.Error{Kind: resterror.EINTERNAL}).WithRetryable(true), true},
+		{"override in the chain", &resterror.Error{
+			Op:  "UserService.FindUserByID",
+			Err: (&resterror.Error{Status: 503, Transient: true}).WithRetryable(true),
+		}, true},
+		{"override of a transient error", (&resterror.Error{Transient: true}).WithRetryable(false), false},
+	}
+	for _, tt := range tests {
+		if got := resterror.IsRetryable(tt.err); got != tt.want {
+			t.Errorf("%s: IsRetryable()=%v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package error
+
+import "log/slog"
+
+// Severity is how severe an error is for operators, which decides the level
+// it is logged at, see Handler.
+type Severity int
+
+// Severities, from the least to the most severe. SeverityDefault means the
+// severity is derived from the status of the error.
+const (
+	SeverityDefault Severity = iota
+	SeverityDebug
+	SeverityInfo
+	SeverityWarn
+	SeverityError
+	SeverityCritical
+)
+
+// LevelCritical is the slog level of SeverityCritical, above slog.LevelError.
+const LevelCritical = slog.LevelError + 4
+
+// Level returns the slog level of s. SeverityDefault returns
+// slog.LevelError.
+func (s Severity) Level() slog.Level {
+	switch s {
+	case SeverityDebug:
+		return slog.LevelDebug
+	case SeverityInfo:
+		return slog.LevelInfo
+	case SeverityWarn:
+		return slog.LevelWarn
+	case SeverityCritical:
+		return LevelCritical
+	}
+	return slog.LevelError
+}
+
+// String returns the name of s.
+func (s Severity) String() string {
+	switch s {
+	case SeverityDebug:
+		return "debug"
+	case SeverityInfo:
+		return "info"
+	case SeverityWarn:
+		return "warn"
+	case SeverityError:
+		return "error"
+	case SeverityCritical:
+		return "critical"
+	}
+	return "default"
+}
+
+// SeverityOf returns the first Severity set in the chain of err, or
+// SeverityDefault if there is none.
+func SeverityOf(err error) Severity {
+	return DefaultRegistry().severityOf(err)
+}
+
+// severityOf is like SeverityOf but resolves joined errors with the join
+// policy of r.
+func (r *Registry) severityOf(err error) Severity {
+	if e, ok := r.lookup(err, func(e *Error) bool { return e.Severity != SeverityDefault }); ok {
+		return e.Severity
+	}
+	return SeverityDefault
+}
@@ -0,0 +1,28 @@
+package error_test
+
+import (
+	"errors"
+	"log/slog"
+	"testing"
+
+	resterror "github.com/truescotian/resterror"
+)
+
+func TestSeverityOf(t *testing.T) {
+	tests := []struct {
+		err   error
+		want  resterror.Severity
+		level slog.Level
+	}{
+		{errors.New("boom"), resterror.SeverityDefault, slog.LevelError},
+		{&resterror.Error{Kind: resterror.EINVALID, Severity: resterror.SeverityInfo}, resterror.SeverityInfo, slog.LevelInfo},
+		{&resterror.Error{Op: "CreateUser", Err: &resterror.Error{Severity: resterror.SeverityWarn}}, resterror.SeverityWarn, slog.LevelWarn},
+		{&resterror.Error{Severity: resterror.SeverityCritical, Err: &resterror.Error{Severity: resterror.SeverityDebug}}, resterror.SeverityCritical, resterror.LevelCritical},
+	}
+	for _, tt := range tests {
+		got := resterror.SeverityOf(tt.err)
+		if got != tt.want || got.Level() != tt.level {
+			t.Fatalf("SeverityOf(%v)=%v (%v), want %v (%v)", tt.err, got, got.Level(), tt.want, tt.level)
+		}
+	}
+}
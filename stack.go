@@ -0,0 +1,46 @@
+package error
+
+import "runtime"
+
+// maxStackDepth caps the number of frames captured for an error.
+const maxStackDepth = 32
+
+// captureStack returns the program counters of the stack of the caller,
+// skipping skip frames above it, if the errors of kind capture their stack
+// in r, see SetCaptureStackFor.
+func (r *Registry) captureStack(kind string, skip int) []uintptr {
+	if !r.capturesStackFor(kind) {
+		return nil
+	}
+	var pcs [maxStackDepth]uintptr
+	n := runtime.Callers(skip+2, pcs[:])
+	return pcs[:n:n]
+}
+
+func (r *Registry) capturesStackFor(kind string) bool {
+	if r.captureStackFor != nil {
+		return r.captureStackFor(kind)
+	}
+	return r.StatusForKind(kind) >= 500
+}
+
+// Frames returns the call stack captured when e was created by a
+// constructor, such as NewError or Wrap, innermost call first, for the code
+// which doesn't report its errors with an Op. The frames are printed by %+v,
+// see Format.
+//
+// Returns nil if no stack was captured, see SetCaptureStackFor.
+func (e *Error) Frames() []runtime.Frame {
+	if len(e.stack) == 0 {
+		return nil
+	}
+	var frames []runtime.Frame
+	iter := runtime.CallersFrames(e.stack)
+	for {
+		frame, more := iter.Next()
+		frames = append(frames, frame)
+		if !more {
+			return frames
+		}
+	}
+}
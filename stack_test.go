@@ -0,0 +1,81 @@
+package error_test
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	resterror "github.com/truescotian/resterror"
+)
+
+func TestFrames(t *testing.T) {
+	e := resterror.NewError("FindUser", http.StatusInternalServerError, "", resterror.EINTERNAL, nil)
+	frames := e.Frames()
+	if len(frames) == 0 {
+		t.Fatal("no frames captured for a 500")
+	}
+	if !strings.HasSuffix(frames[0].Function, ".TestFrames") {
+		t.Fatalf("first frame=%s, want the caller of NewError", frames[0].Function)
+	}
+	if len(resterror.WrapKind("GetUser", e).Frames()) == 0 {
+		t.Fatal("no frames captured when wrapping a 500")
+	}
+
+	e = resterror.NewError("FindUser", http.StatusNotFound, "", resterror.ENOTFOUND, nil)
+	if frames := e.Frames(); frames != nil {
+		t.Fatalf("frames captured for a 404: %v", frames)
+	}
+	if frames := resterror.WrapKind("GetUser", e).Frames(); frames != nil {
+		t.Fatalf("frames captured when wrapping a 404: %v", frames)
+	}
+}
+
+func TestSetCaptureStackFor(t *testing.T) {
+	defer resterror.SetDefaultRegistry(resterror.DefaultRegistry())
+
+	resterror.SetCaptureStackFor(func(kind string) bool { return kind == resterror.ENOTFOUND })
+	if len(resterror.NewError("FindUser", 404, "", resterror.ENOTFOUND, nil).Frames()) == 0 {
+		t.Fatal("no frames captured for a 404")
+	}
+	if resterror.NewError("FindUser", 500, "", resterror.EINTERNAL, nil).Frames() != nil {
+		t.Fatal("frames captured for a 500")
+	}
+}
+
+func BenchmarkNewError(b *testing.B) {
+	for _, bb := range []struct {
+		name   string
+		kind   string
+		status int
+	}{
+		{"404", resterror.ENOTFOUND, http.StatusNotFound},
+		{"500", resterror.EINTERNAL, http.StatusInternalServerError},
+	} {
+		b.Run(bb.name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				resterror.NewError("FindUser", bb.status, "", bb.kind, nil)
+			}
+		})
+	}
+}
+
+type userService struct{}
+
+func (s *userService) CreateUser() error {
+	return resterror.NewAuto(http.StatusConflict, "Username is taken.", resterror.EEXIST, nil)
+}
+
+func findUser() error {
+	return resterror.NewAuto(http.StatusNotFound, "", resterror.ENOTFOUND, nil)
+}
+
+func TestNewAuto(t *testing.T) {
+	if got := resterror.GetOp((&userService{}).CreateUser()); got != "resterror_test.userService.CreateUser" {
+		t.Fatalf("Op=%q", got)
+	}
+	e, _ := resterror.AsError(findUser())
+	if e.Op != "resterror_test.findUser" || e.Kind != resterror.ENOTFOUND || e.Status != 404 {
+		t.Fatalf("NewAuto()=%#v", e)
+	}
+}
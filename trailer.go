@@ -0,0 +1,76 @@
+package error
+
+import (
+	"net/http"
+	"strings"
+)
+
+// Trailers carrying an error which occurred after the response headers were
+// sent, see WriteErrorTrailer.
+const (
+	TrailerKind    = "X-Error-Kind"
+	TrailerMessage = "X-Error-Message"
+)
+
+// declareErrorTrailers declares the error trailers in the headers of w, so
+// clients know to expect them. It must be called before the headers are
+// written.
+func declareErrorTrailers(w http.ResponseWriter) {
+	w.Header().Add("Trailer", TrailerKind)
+	w.Header().Add("Trailer", TrailerMessage)
+}
+
+// WriteErrorTrailer writes the kind and client-safe message of err in the
+// trailers of w. It is meant for errors occurring mid-stream, once the status
+// code has been sent and can't be changed.
+//
+// Trailers should be declared before the headers are written, which Handler
+// does when Streaming is set. Otherwise they are sent undeclared, which only
+// works for chunked HTTP/1.1 and HTTP/2 responses.
+func WriteErrorTrailer(w http.ResponseWriter, err error) {
+	DefaultRegistry().WriteErrorTrailer(w, err)
+}
+
+// WriteErrorTrailer is like the package-level WriteErrorTrailer but resolves
+// err with r.
+func (r *Registry) WriteErrorTrailer(w http.ResponseWriter, err error) {
+	e := r.coerce(err)
+	prefix := http.TrailerPrefix
+	for _, t := range w.Header().Values("Trailer") {
+		if strings.Contains(t, TrailerKind) {
+			prefix = ""
+			break
+		}
+	}
+	w.Header().Set(prefix+TrailerKind, r.errorKind(e))
+	if msg, ok := sanitizeHeaderValue(r.ClientSafeMessage(e)); ok {
+		w.Header().Set(prefix+TrailerMessage, msg)
+	}
+}
+
+// responseWriter records whether the headers of a response were written.
+type responseWriter struct {
+	http.ResponseWriter
+	wroteHeader bool
+}
+
+func (w *responseWriter) WriteHeader(status int) {
+	w.wroteHeader = true
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *responseWriter) Write(b []byte) (int, error) {
+	w.wroteHeader = true
+	return w.ResponseWriter.Write(b)
+}
+
+// Flush implements http.Flusher, which streaming handlers rely on.
+func (w *responseWriter) Flush() {
+	w.wroteHeader = true
+	http.NewResponseController(w.ResponseWriter).Flush()
+}
+
+// Unwrap returns the wrapped writer, for http.ResponseController.
+func (w *responseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
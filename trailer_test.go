@@ -0,0 +1,68 @@
+package error_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	resterror "github.com/truescotian/resterror"
+)
+
+func TestHandler_Streaming(t *testing.T) {
+	h := &resterror.Handler{
+		Fn: func(w http.ResponseWriter, r *http.Request) error {
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`[{"id":1},`))
+			w.(http.Flusher).Flush()
+			return &resterror.Error{Op: "ExportUsers", Kind: resterror.EINTERNAL, Err: io.ErrUnexpectedEOF}
+		},
+		Logger:    discard,
+		Streaming: true,
+	}
+	srv := httptest.NewServer(h)
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if resp.StatusCode != http.StatusOK || string(body) != `[{"id":1},` {
+		t.Fatalf("status=%d body=%s", resp.StatusCode, body)
+	}
+	if kind := resp.Trailer.Get(resterror.TrailerKind); kind != resterror.EINTERNAL {
+		t.Fatalf("%s trailer=%q", resterror.TrailerKind, kind)
+	}
+	if msg := resp.Trailer.Get(resterror.TrailerMessage); msg != resterror.MsgInternal {
+		t.Fatalf("%s trailer=%q", resterror.TrailerMessage, msg)
+	}
+}
+
+func TestWriteErrorTrailer_Undeclared(t *testing.T) {
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("partial"))
+		w.(http.Flusher).Flush()
+		resterror.WriteErrorTrailer(w, &resterror.Error{Kind: resterror.ENOTFOUND, Status: 404, Message: "Export not found."})
+	})
+	srv := httptest.NewServer(h)
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	io.ReadAll(resp.Body)
+	if kind := resp.Trailer.Get(resterror.TrailerKind); kind != resterror.ENOTFOUND {
+		t.Fatalf("%s trailer=%q", resterror.TrailerKind, kind)
+	}
+	if msg := resp.Trailer.Get(resterror.TrailerMessage); msg != "Export not found." {
+		t.Fatalf("%s trailer=%q", resterror.TrailerMessage, msg)
+	}
+}
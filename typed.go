@@ -0,0 +1,49 @@
+package error
+
+// Typed wraps an *Error with a strongly-typed payload, such as the limits of
+// a quota or the diff of a conflict, so that callers don't have to encode it
+// in Message and parse it back:
+//
+//	return resterror.NewTyped(resterror.Conflict(op, "Order was modified."), diff)
+//
+//	if diff, ok := resterror.PayloadAs[OrderDiff](err); ok { ... }
+//
+// The payload is for the code handling the error, it isn't serialized, use
+// ErrorDetails for the data meant for clients. The wrapped *Error resolves
+// the kind, status and message as usual.
+type Typed[T any] struct {
+	Err     *Error
+	Payload T
+}
+
+// NewTyped returns e carrying payload.
+func NewTyped[T any](e *Error, payload T) *Typed[T] {
+	return &Typed[T]{Err: e, Payload: payload}
+}
+
+// Error returns the Error() string of the wrapped *Error, or an empty
+// string if there is none, as for a zero Typed.
+func (t *Typed[T]) Error() string {
+	if t == nil || t.Err == nil {
+		return ""
+	}
+	return t.Err.Error()
+}
+
+// Unwrap returns the wrapped *Error, or nil if there is none.
+func (t *Typed[T]) Unwrap() error {
+	if t == nil || t.Err == nil {
+		return nil
+	}
+	return t.Err
+}
+
+// PayloadAs returns the payload of the first *Typed[T] found in the chain of
+// err, through any wrapper, and whether there is one.
+func PayloadAs[T any](err error) (T, bool) {
+	if t, ok := as[*Typed[T]](err); ok {
+		return t.Payload, true
+	}
+	var zero T
+	return zero, false
+}
@@ -0,0 +1,56 @@
+package error_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	resterror "github.com/truescotian/resterror"
+)
+
+type quota struct {
+	Limit, Used int
+}
+
+func TestTyped(t *testing.T) {
+	inner := resterror.NewTyped(resterror.NewError("CreateProject", 0, "Project limit reached.", resterror.EQUOTA, nil), quota{Limit: 10, Used: 10})
+	err := fmt.Errorf("handler: %w", &resterror.Error{Op: "POST /projects", Err: inner})
+
+	q, ok := resterror.PayloadAs[quota](err)
+	if !ok || q != (quota{Limit: 10, Used: 10}) {
+		t.Fatalf("PayloadAs()=%+v, %v", q, ok)
+	}
+	if _, ok := resterror.PayloadAs[string](err); ok {
+		t.Fatal("PayloadAs[string]() matched")
+	}
+
+	// The wrapped *Error is resolved as usual, and the payload isn't sent.
+	if !resterror.IsQuota(err) || resterror.ErrorMessage(err) != "Project limit reached." {
+		t.Fatalf("kind=%q message=%q", resterror.ErrorKind(err), resterror.ErrorMessage(err))
+	}
+	if got, want := err.Error(), "handler: POST /projects: CreateProject: <quota_exceeded> Project limit reached."; got != want {
+		t.Fatalf("Error()=%q, want %q", got, want)
+	}
+	body, _ := resterror.Coerce(err).ResponseBody()
+	if strings.Contains(string(body), "Limit") {
+		t.Fatalf("payload is serialized: %s", body)
+	}
+}
+
+func TestTyped_NilError(t *testing.T) {
+	for _, typed := range []*resterror.Typed[quota]{{}, resterror.NewTyped(nil, quota{Limit: 1})} {
+		if got := typed.Error(); got != "" {
+			t.Fatalf("Error()=%q", got)
+		}
+		if typed.Unwrap() != nil {
+			t.Fatal("Unwrap() != nil")
+		}
+		err := &resterror.Error{Op: "CreateProject", Err: typed}
+		if got := resterror.ErrorKind(err); got != resterror.EINTERNAL {
+			t.Fatalf("ErrorKind()=%q", got)
+		}
+		if q, ok := resterror.PayloadAs[quota](err); !ok || q != typed.Payload {
+			t.Fatalf("PayloadAs()=%+v, %v", q, ok)
+		}
+	}
+}
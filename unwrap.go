@@ -0,0 +1,63 @@
+package error
+
+import "reflect"
+
+// visit calls fn for err and the errors it wraps, depth first like
+// errors.Is, until fn returns true. Unlike the standard library, it visits
+// at most maxDepth errors, so that a self-referential chain (e.Err = e)
+// can't hang it. Like lookup and Walk, it treats longer chains as if they
+// ended there.
+func visit(err error, fn func(error) bool) bool {
+	n := 0
+	var rec func(error) bool
+	rec = func(err error) bool {
+		for err != nil && n < maxDepth {
+			n++
+			if fn(err) {
+				return true
+			}
+			switch u := err.(type) {
+			case interface{ Unwrap() error }:
+				err = u.Unwrap()
+			case interface{ Unwrap() []error }:
+				for _, branch := range u.Unwrap() {
+					if rec(branch) {
+						return true
+					}
+				}
+				return false
+			default:
+				return false
+			}
+		}
+		return false
+	}
+	return rec(err)
+}
+
+// is is like errors.Is, but capped like lookup, see visit.
+func is(err, target error) bool {
+	comparable := target == nil || reflect.TypeOf(target).Comparable()
+	return visit(err, func(err error) bool {
+		if comparable && err == target {
+			return true
+		}
+		x, ok := err.(interface{ Is(error) bool })
+		return ok && x.Is(target)
+	})
+}
+
+// as is like errors.As, but capped like lookup, see visit. It returns the
+// first error of the chain of err of type T.
+func as[T any](err error) (T, bool) {
+	var target T
+	found := visit(err, func(err error) bool {
+		if v, ok := err.(T); ok {
+			target = v
+			return true
+		}
+		x, ok := err.(interface{ As(interface{}) bool })
+		return ok && x.As(&target)
+	})
+	return target, found
+}
@@ -0,0 +1,93 @@
+package error
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"sort"
+)
+
+// ValidationError is an EINVALID error which reports every field of a request
+// that failed validation, so end users can fix them all at once.
+type ValidationError struct {
+	// Op is the logical operation which validated the fields.
+	Op string
+
+	// Fields maps each invalid field to a human-readable reason.
+	// Ex: { "username": "is required" }.
+	Fields map[string]string
+}
+
+// Error returns the fields and their reasons sorted by field name, on a
+// single line, after the Op if any.
+func (v *ValidationError) Error() string {
+	var buf bytes.Buffer
+	if v.Op != "" {
+		fmt.Fprintf(&buf, "%s: ", v.Op)
+	}
+	fmt.Fprintf(&buf, "<%s> ", EINVALID)
+
+	for i, name := range v.names() {
+		if i > 0 {
+			buf.WriteString("; ")
+		}
+		fmt.Fprintf(&buf, "%s: %s", name, v.Fields[name])
+	}
+	return buf.String()
+}
+
+// names returns the names of the fields of v, sorted.
+func (v *ValidationError) names() []string {
+	names := make([]string, 0, len(v.Fields))
+	for name := range v.Fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Unwrap returns v as an *Error of kind EINVALID, so that AsError and Coerce
+// classify a ValidationError correctly. Its fields are sent to the client as
+// FieldViolation error details, sorted by name.
+func (v *ValidationError) Unwrap() error {
+	var details []ErrorDetail
+	for _, name := range v.names() {
+		details = append(details, FieldViolation{Field: name, Description: v.Fields[name]})
+	}
+	return &Error{
+		Op:           v.Op,
+		Kind:         EINVALID,
+		Status:       http.StatusUnprocessableEntity,
+		Message:      MsgValidation,
+		ErrorDetails: details,
+	}
+}
+
+// MergeValidation combines the fields of every *ValidationError in errs into a
+// single *ValidationError, so validators can run independently. Nil errors and
+// errors other than *ValidationError are ignored.
+//
+// When several errors report the same field their reasons are joined with
+// "; " rather than overwritten. Returns nil if no field failed validation.
+func MergeValidation(errs ...error) error {
+	var merged *ValidationError
+	for _, err := range errs {
+		v, ok := as[*ValidationError](err)
+		if !ok {
+			continue
+		}
+		for name, reason := range v.Fields {
+			if merged == nil {
+				merged = &ValidationError{Op: v.Op, Fields: make(map[string]string)}
+			}
+			if prev, ok := merged.Fields[name]; ok {
+				reason = prev + "; " + reason
+			}
+			merged.Fields[name] = reason
+		}
+	}
+	if merged == nil {
+		return nil
+	}
+	return merged
+}
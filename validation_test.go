@@ -0,0 +1,59 @@
+package error_test
+
+import (
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	resterror "github.com/truescotian/resterror"
+)
+
+func TestMergeValidation(t *testing.T) {
+	a := &resterror.ValidationError{Op: "CreateUser", Fields: map[string]string{"username": "is required"}}
+	b := &resterror.ValidationError{Fields: map[string]string{"email": "is invalid"}}
+
+	err := resterror.MergeValidation(a, nil, b)
+	v, ok := err.(*resterror.ValidationError)
+	if !ok {
+		t.Fatalf("MergeValidation()=%T", err)
+	}
+	want := map[string]string{"username": "is required", "email": "is invalid"}
+	if !reflect.DeepEqual(v.Fields, want) {
+		t.Fatalf("Fields=%v", v.Fields)
+	}
+	if kind := resterror.Coerce(err).Kind; kind != resterror.EINVALID {
+		t.Fatalf("kind=%q", kind)
+	}
+}
+
+func TestMergeValidation_OverlappingField(t *testing.T) {
+	a := &resterror.ValidationError{Fields: map[string]string{"username": "is required"}}
+	b := &resterror.ValidationError{Fields: map[string]string{"username": "is too long"}}
+
+	v := resterror.MergeValidation(a, b).(*resterror.ValidationError)
+	if got := v.Fields["username"]; got != "is required; is too long" {
+		t.Fatalf("username=%q", got)
+	}
+	if got := v.Error(); got != "<invalid> username: is required; is too long" {
+		t.Fatalf("Error()=%q", got)
+	}
+}
+
+func TestMergeValidation_Nil(t *testing.T) {
+	if err := resterror.MergeValidation(nil, nil); err != nil {
+		t.Fatalf("MergeValidation()=%v", err)
+	}
+}
+
+func TestValidationError_ResponseBody(t *testing.T) {
+	err := &resterror.ValidationError{Op: "CreateUser", Fields: map[string]string{"username": "is required", "email": "is invalid"}}
+
+	w := httptest.NewRecorder()
+	resterror.WriteError(w, err)
+	want := `{"kind":"invalid","status":422,"message":"One or more fields are invalid.","error_details":[` +
+		`{"@type":"field_violation","field":"email","description":"is invalid"},` +
+		`{"@type":"field_violation","field":"username","description":"is required"}]}`
+	if w.Code != 422 || w.Body.String() != want {
+		t.Fatalf("status=%d body=%s, want %s", w.Code, w.Body, want)
+	}
+}